@@ -61,3 +61,69 @@ func (r *Runner) Withdraw(ctx context.Context, character string, code string, qt
 		},
 	}, nil
 }
+
+// DepositGold deposits the given quantity of gold into the bank
+func (r *Runner) DepositGold(ctx context.Context, character string, qty int) (*GoldResponse, error) {
+	r.BankMutex.Lock()
+	defer r.BankMutex.Unlock()
+	resp, err := r.Client.ActionDepositBankGoldMyNameActionBankDepositGoldPostWithResponse(ctx, character, client.ActionDepositBankGoldMyNameActionBankDepositGoldPostJSONRequestBody{
+		Quantity: qty,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to deposit gold: %w", err)
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return nil, fmt.Errorf("status failure (%d), message: %s", resp.StatusCode(), resp.Body)
+	}
+
+	return &GoldResponse{
+		BankGold: resp.JSON200.Data.Bank.Quantity,
+		Response: Response{
+			CharacterResponse: models.Character{CharacterSchema: resp.JSON200.Data.Character},
+			CooldownSchema:    resp.JSON200.Data.Cooldown,
+		},
+	}, nil
+}
+
+// WithdrawGold withdraws the given quantity of gold from the bank
+func (r *Runner) WithdrawGold(ctx context.Context, character string, qty int) (*GoldResponse, error) {
+	r.BankMutex.Lock()
+	defer r.BankMutex.Unlock()
+	resp, err := r.Client.ActionWithdrawBankGoldMyNameActionBankWithdrawGoldPostWithResponse(ctx, character, client.ActionWithdrawBankGoldMyNameActionBankWithdrawGoldPostJSONRequestBody{
+		Quantity: qty,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to withdraw gold: %w", err)
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return nil, fmt.Errorf("status failure (%d), message: %s", resp.StatusCode(), resp.Body)
+	}
+
+	return &GoldResponse{
+		BankGold: resp.JSON200.Data.Bank.Quantity,
+		Response: Response{
+			CharacterResponse: models.Character{CharacterSchema: resp.JSON200.Data.Character},
+			CooldownSchema:    resp.JSON200.Data.Cooldown,
+		},
+	}, nil
+}
+
+// BuyBankExpansion buys the next bank expansion with the character's gold, and assumes the
+// character is in the correct map position
+func (r *Runner) BuyBankExpansion(ctx context.Context, character string) (*ExpansionResponse, error) {
+	resp, err := r.Client.ActionBuyBankExpansionMyNameActionBankBuyExpansionPostWithResponse(ctx, character)
+	if err != nil {
+		return nil, fmt.Errorf("failed to buy bank expansion: %w", err)
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return nil, fmt.Errorf("status failure (%d), message: %s", resp.StatusCode(), resp.Body)
+	}
+
+	return &ExpansionResponse{
+		Price: resp.JSON200.Data.Transaction.Price,
+		Response: Response{
+			CharacterResponse: models.Character{CharacterSchema: resp.JSON200.Data.Character},
+			CooldownSchema:    resp.JSON200.Data.Cooldown,
+		},
+	}, nil
+}
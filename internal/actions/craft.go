@@ -34,3 +34,28 @@ func (r *Runner) Craft(ctx context.Context, character string, code string, quant
 		},
 	}, nil
 }
+
+// Recycle recycles the given item, with the given quantity and assumes the character is in the
+// correct map position
+func (r *Runner) Recycle(ctx context.Context, character string, code string, quantity int) (*RecycleResponse, error) {
+	req := client.ActionRecyclingMyNameActionRecyclingPostJSONRequestBody{
+		Code:     code,
+		Quantity: &quantity,
+	}
+
+	resp, err := r.Client.ActionRecyclingMyNameActionRecyclingPostWithResponse(ctx, character, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to recycle %s (%d): %w", code, quantity, err)
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return nil, fmt.Errorf("status failure (%d), message: %s", resp.StatusCode(), resp.Body)
+	}
+
+	return &RecycleResponse{
+		Items: resp.JSON200.Data.Details.Items,
+		Response: Response{
+			CharacterResponse: models.Character{CharacterSchema: resp.JSON200.Data.Character},
+			CooldownSchema:    resp.JSON200.Data.Cooldown,
+		},
+	}, nil
+}
@@ -0,0 +1,37 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/promiseofcake/artifactsmmo-go-client/client"
+
+	"github.com/promiseofcake/artifactsmmo-engine/internal/models"
+)
+
+// DeleteItem destroys an item from the character's inventory
+func (r *Runner) DeleteItem(ctx context.Context, character string, code string, qty int) (*DeleteResponse, error) {
+	resp, err := r.Client.ActionDeleteItemMyNameActionDeletePostWithResponse(
+		ctx,
+		character,
+		client.ActionDeleteItemMyNameActionDeletePostJSONRequestBody{
+			Code:     code,
+			Quantity: qty,
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete %s (%d): %w", code, qty, err)
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return nil, fmt.Errorf("status failure (%d), message: %s", resp.StatusCode(), resp.Body)
+	}
+
+	return &DeleteResponse{
+		Item: resp.JSON200.Data.Item,
+		Response: Response{
+			CharacterResponse: models.Character{CharacterSchema: resp.JSON200.Data.Character},
+			CooldownSchema:    resp.JSON200.Data.Cooldown,
+		},
+	}, nil
+}
@@ -0,0 +1,63 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/promiseofcake/artifactsmmo-go-client/client"
+
+	"github.com/promiseofcake/artifactsmmo-engine/internal/models"
+)
+
+// Buy buys the given item from the grand exchange at the given price per item, and assumes
+// the character is in the correct map position
+func (r *Runner) Buy(ctx context.Context, character string, code string, quantity int, price int) (*GEResponse, error) {
+	req := client.ActionGeBuyItemMyNameActionGeBuyPostJSONRequestBody{
+		Code:     code,
+		Quantity: quantity,
+		Price:    price,
+	}
+
+	resp, err := r.Client.ActionGeBuyItemMyNameActionGeBuyPostWithResponse(ctx, character, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to buy %s (%d): %w", code, quantity, err)
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return nil, fmt.Errorf("status failure (%d), message: %s", resp.StatusCode(), resp.Body)
+	}
+
+	return &GEResponse{
+		Transaction: resp.JSON200.Data.Transaction,
+		Response: Response{
+			CharacterResponse: models.Character{CharacterSchema: resp.JSON200.Data.Character},
+			CooldownSchema:    resp.JSON200.Data.Cooldown,
+		},
+	}, nil
+}
+
+// Sell sells the given item on the grand exchange at the given price per item, and assumes
+// the character is in the correct map position
+func (r *Runner) Sell(ctx context.Context, character string, code string, quantity int, price int) (*GEResponse, error) {
+	req := client.ActionGeSellItemMyNameActionGeSellPostJSONRequestBody{
+		Code:     code,
+		Quantity: quantity,
+		Price:    price,
+	}
+
+	resp, err := r.Client.ActionGeSellItemMyNameActionGeSellPostWithResponse(ctx, character, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sell %s (%d): %w", code, quantity, err)
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return nil, fmt.Errorf("status failure (%d), message: %s", resp.StatusCode(), resp.Body)
+	}
+
+	return &GEResponse{
+		Transaction: resp.JSON200.Data.Transaction,
+		Response: Response{
+			CharacterResponse: models.Character{CharacterSchema: resp.JSON200.Data.Character},
+			CooldownSchema:    resp.JSON200.Data.Cooldown,
+		},
+	}, nil
+}
@@ -40,3 +40,45 @@ type BankResponse struct {
 	BankItems []client.SimpleItemSchema
 	Item      client.ItemSchema
 }
+
+// GoldResponse wraps a generic Response with the gold held in the bank afterwards
+type GoldResponse struct {
+	Response
+	BankGold int
+}
+
+// ExpansionResponse wraps a generic Response with the price paid for a bank expansion
+type ExpansionResponse struct {
+	Response
+	Price int
+}
+
+// GEResponse wraps a generic Response with Grand Exchange related data
+type GEResponse struct {
+	Response
+	Transaction client.GETransactionSchema
+}
+
+// RestResponse wraps a generic Response with the HP restored by resting
+type RestResponse struct {
+	Response
+	HpRestored int
+}
+
+// UseResponse wraps a generic Response with the item used
+type UseResponse struct {
+	Response
+	Item client.ItemSchema
+}
+
+// RecycleResponse wraps a generic Response with the materials recovered by recycling
+type RecycleResponse struct {
+	Response
+	Items []client.DropSchema
+}
+
+// DeleteResponse wraps a generic Response with the item deleted
+type DeleteResponse struct {
+	Response
+	Item client.SimpleItemSchema
+}
@@ -0,0 +1,56 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/promiseofcake/artifactsmmo-go-client/client"
+
+	"github.com/promiseofcake/artifactsmmo-engine/internal/models"
+)
+
+// Rest recovers HP for the given character, the cooldown scales with the HP restored
+func (r *Runner) Rest(ctx context.Context, character string) (*RestResponse, error) {
+	resp, err := r.Client.ActionRestMyNameActionRestPostWithResponse(ctx, character)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rest: %w", err)
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return nil, fmt.Errorf("status failure (%d), message: %s", resp.StatusCode(), resp.Body)
+	}
+
+	return &RestResponse{
+		HpRestored: resp.JSON200.Data.HpRestored,
+		Response: Response{
+			CharacterResponse: models.Character{CharacterSchema: resp.JSON200.Data.Character},
+			CooldownSchema:    resp.JSON200.Data.Cooldown,
+		},
+	}, nil
+}
+
+// UseItem uses (consumes) an item from the character's inventory, such as food
+func (r *Runner) UseItem(ctx context.Context, character string, code string, qty int) (*UseResponse, error) {
+	resp, err := r.Client.ActionUseItemMyNameActionUsePostWithResponse(
+		ctx,
+		character,
+		client.ActionUseItemMyNameActionUsePostJSONRequestBody{
+			Code:     code,
+			Quantity: qty,
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to use item %s (%d): %w", code, qty, err)
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return nil, fmt.Errorf("status failure (%d), message: %s", resp.StatusCode(), resp.Body)
+	}
+
+	return &UseResponse{
+		Item: resp.JSON200.Data.Item,
+		Response: Response{
+			CharacterResponse: models.Character{CharacterSchema: resp.JSON200.Data.Character},
+			CooldownSchema:    resp.JSON200.Data.Cooldown,
+		},
+	}, nil
+}
@@ -16,6 +16,11 @@ type Runner struct {
 	Client      *client.ClientWithResponses
 	BankMutex   sync.Mutex
 	RefineMutex sync.Mutex
+
+	// contentMutex guards the cached game content
+	contentMutex  sync.Mutex
+	contentLevels map[string]int
+	recipes       map[string][]client.ItemSchema
 }
 
 type retryLogger struct {
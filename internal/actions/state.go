@@ -36,6 +36,54 @@ func (r *Runner) GetBankItems(ctx context.Context) (models.SimpleItems, error) {
 	return bank, nil
 }
 
+// GetBankGold returns the quantity of gold held in the bank
+func (r *Runner) GetBankGold(ctx context.Context) (int, error) {
+	bank, err := r.GetBankDetails(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return bank.Gold, nil
+}
+
+// GetBankDetails returns the bank's slots, expansions and gold
+func (r *Runner) GetBankDetails(ctx context.Context) (models.Bank, error) {
+	resp, err := r.Client.GetBankDetailsMyBankGetWithResponse(ctx)
+	if err != nil {
+		return models.Bank{}, fmt.Errorf("failed to get bank details: %w", err)
+	}
+
+	if resp.StatusCode() != http.StatusOK {
+		return models.Bank{}, fmt.Errorf("failed to get bank details: %s (%d)", resp.Body, resp.StatusCode())
+	}
+
+	b := resp.JSON200.Data
+	return models.Bank{
+		Slots:             b.Slots,
+		Expansions:        b.Expansions,
+		NextExpansionCost: b.NextExpansionCost,
+		Gold:              b.Gold,
+	}, nil
+}
+
+// GetMyCharacters returns current info and status about all of your characters
+func (r *Runner) GetMyCharacters(ctx context.Context) ([]models.Character, error) {
+	resp, err := r.Client.GetMyCharactersMyCharactersGetWithResponse(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get characters: %w", err)
+	}
+
+	if resp.StatusCode() != http.StatusOK {
+		return nil, fmt.Errorf("failed to get characters: %s (%d)", resp.Body, resp.StatusCode())
+	}
+
+	var characters []models.Character
+	for _, c := range resp.JSON200.Data {
+		characters = append(characters, models.Character{CharacterSchema: c})
+	}
+
+	return characters, nil
+}
+
 // GetMyCharacterInfo returns current info and status about your own specific character
 func (r *Runner) GetMyCharacterInfo(ctx context.Context, character string) (models.Character, error) {
 	resp, err := r.Client.GetMyCharactersMyCharactersGetWithResponse(ctx)
@@ -144,16 +192,54 @@ func (r *Runner) GetItem(ctx context.Context, code string) (models.Item, error)
 	return models.Item{ItemSchema: resp.JSON200.Data.Item}, nil
 }
 
+// GetSellPrice returns the grand exchange sell price of an item, or 0 if it can't be sold
+func (r *Runner) GetSellPrice(ctx context.Context, code string) (int, error) {
+	ge, err := r.getGEItem(ctx, code)
+	if err != nil || ge.SellPrice == nil {
+		return 0, err
+	}
+	return *ge.SellPrice, nil
+}
+
+// GetBuyPrice returns the grand exchange buy price of an item, or 0 if it can't be bought
+func (r *Runner) GetBuyPrice(ctx context.Context, code string) (int, error) {
+	ge, err := r.getGEItem(ctx, code)
+	if err != nil || ge.BuyPrice == nil || ge.Stock == 0 {
+		return 0, err
+	}
+	return *ge.BuyPrice, nil
+}
+
+// getGEItem returns the grand exchange listing of an item, which is empty if it isn't listed
+func (r *Runner) getGEItem(ctx context.Context, code string) (client.GEItemSchema, error) {
+	resp, err := r.Client.GetGeItemGeCodeGetWithResponse(ctx, code)
+	if err != nil {
+		return client.GEItemSchema{}, fmt.Errorf("failed to get grand exchange item with code: %s %w", code, err)
+	}
+
+	if resp.StatusCode() == http.StatusNotFound {
+		return client.GEItemSchema{}, nil
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return client.GEItemSchema{}, fmt.Errorf("failed to get grand exchange item: %s (%d)", resp.Body, resp.StatusCode())
+	}
+	return resp.JSON200.Data, nil
+}
+
 // GetItems searches for an item
 func (r *Runner) GetItems(ctx context.Context, min, max int, skill string, material string) (models.Items, error) {
 	s := client.GetAllItemsItemsGetParamsCraftSkill(skill)
 
-	resp, err := r.Client.GetAllItemsItemsGetWithResponse(ctx, &client.GetAllItemsItemsGetParams{
-		CraftSkill:    &s,
-		CraftMaterial: &material,
-		MinLevel:      &min,
-		MaxLevel:      &max,
-	})
+	params := &client.GetAllItemsItemsGetParams{
+		CraftSkill: &s,
+		MinLevel:   &min,
+		MaxLevel:   &max,
+	}
+	// an empty material searches every item crafted by the skill
+	if material != "" {
+		params.CraftMaterial = &material
+	}
+	resp, err := r.Client.GetAllItemsItemsGetWithResponse(ctx, params)
 	if err != nil {
 		return models.Items{}, err
 	}
@@ -162,8 +248,45 @@ func (r *Runner) GetItems(ctx context.Context, min, max int, skill string, mater
 		return models.Items{}, err
 	}
 
+	return craftableItems(resp.JSON200.Data)
+}
+
+// GetRecipes returns every item crafted by the given skill, at any level. The recipes are cached,
+// the items returned are the caller's to modify.
+func (r *Runner) GetRecipes(ctx context.Context, skill string) (models.Items, error) {
+	r.contentMutex.Lock()
+	recipes, ok := r.recipes[skill]
+	r.contentMutex.Unlock()
+	if ok {
+		return craftableItems(recipes)
+	}
+
+	s := client.GetAllItemsItemsGetParamsCraftSkill(skill)
+	size := 100
+	resp, err := r.Client.GetAllItemsItemsGetWithResponse(ctx, &client.GetAllItemsItemsGetParams{
+		CraftSkill: &s,
+		Size:       &size,
+	})
+	if err != nil {
+		return models.Items{}, fmt.Errorf("failed to fetch items for skill %s: %w", skill, err)
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return models.Items{}, fmt.Errorf("status failure (%d), message: %s", resp.StatusCode(), resp.Body)
+	}
+
+	r.contentMutex.Lock()
+	defer r.contentMutex.Unlock()
+	if r.recipes == nil {
+		r.recipes = make(map[string][]client.ItemSchema)
+	}
+	r.recipes[skill] = resp.JSON200.Data
+	return craftableItems(resp.JSON200.Data)
+}
+
+// craftableItems returns the items with their craft skill and materials
+func craftableItems(data []client.ItemSchema) (models.Items, error) {
 	var items models.Items
-	for _, i := range resp.JSON200.Data {
+	for _, i := range data {
 		a := models.Item{ItemSchema: i}
 
 		cs, cErr := a.Craft.AsCraftSchema()
@@ -186,6 +309,60 @@ func (r *Runner) GetItems(ctx context.Context, min, max int, skill string, mater
 	return items, nil
 }
 
+// GetSkillContentLevel returns the highest level of any resource to gather, or item to craft, for
+// the given skill. There's nothing left to train a skill on above it. The result is cached.
+func (r *Runner) GetSkillContentLevel(ctx context.Context, skill string) (int, error) {
+	r.contentMutex.Lock()
+	level, ok := r.contentLevels[skill]
+	r.contentMutex.Unlock()
+	if ok {
+		return level, nil
+	}
+
+	size := 100
+	if slices.Contains(models.GatheringSkills, skill) {
+		s := client.GetAllResourcesResourcesGetParamsSkill(skill)
+		resp, err := r.Client.GetAllResourcesResourcesGetWithResponse(ctx, &client.GetAllResourcesResourcesGetParams{
+			Skill: &s,
+			Size:  &size,
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to fetch resources for skill %s: %w", skill, err)
+		}
+		if resp.StatusCode() != http.StatusOK {
+			return 0, fmt.Errorf("status failure (%d), message: %s", resp.StatusCode(), resp.Body)
+		}
+		for _, res := range resp.JSON200.Data {
+			level = max(level, res.Level)
+		}
+	}
+
+	if slices.Contains(models.CraftingSkills, skill) {
+		s := client.GetAllItemsItemsGetParamsCraftSkill(skill)
+		resp, err := r.Client.GetAllItemsItemsGetWithResponse(ctx, &client.GetAllItemsItemsGetParams{
+			CraftSkill: &s,
+			Size:       &size,
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to fetch items for skill %s: %w", skill, err)
+		}
+		if resp.StatusCode() != http.StatusOK {
+			return 0, fmt.Errorf("status failure (%d), message: %s", resp.StatusCode(), resp.Body)
+		}
+		for _, i := range resp.JSON200.Data {
+			level = max(level, i.Level)
+		}
+	}
+
+	r.contentMutex.Lock()
+	defer r.contentMutex.Unlock()
+	if r.contentLevels == nil {
+		r.contentLevels = make(map[string]int)
+	}
+	r.contentLevels[skill] = level
+	return level, nil
+}
+
 // GetMonsters fetches monster world state based upon a given content type
 func (r *Runner) GetMonsters(ctx context.Context, min, max int) (models.Monsters, error) {
 	resp, err := r.Client.GetAllMonstersMonstersGetWithResponse(ctx, &client.GetAllMonstersMonstersGetParams{
@@ -213,6 +390,52 @@ func (r *Runner) GetMonsters(ctx context.Context, min, max int) (models.Monsters
 	return monsters, nil
 }
 
+// GetMonstersByDrop fetches all monsters which drop the given item
+func (r *Runner) GetMonstersByDrop(ctx context.Context, drop string) (models.Monsters, error) {
+	resp, err := r.Client.GetAllMonstersMonstersGetWithResponse(ctx, &client.GetAllMonstersMonstersGetParams{
+		Drop: &drop,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch monsters for drop %s, %w", drop, err)
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return nil, fmt.Errorf("status failure (%d), message: %s", resp.StatusCode(), resp.Body)
+	}
+
+	var monsters models.Monsters
+	for _, m := range resp.JSON200.Data {
+		monster := models.Monster{
+			Name:     m.Name,
+			Code:     m.Code,
+			Level:    m.Level,
+			Location: models.Location{},
+		}
+		monsters = append(monsters, monster)
+	}
+
+	return monsters, nil
+}
+
+// GetResource returns information about a resource
+func (r *Runner) GetResource(ctx context.Context, code string) (models.Resource, error) {
+	resp, err := r.Client.GetResourceResourcesCodeGetWithResponse(ctx, code)
+	if err != nil {
+		return models.Resource{}, fmt.Errorf("failed to get resource with code: %s %w", code, err)
+	}
+
+	if resp.StatusCode() != http.StatusOK {
+		return models.Resource{}, fmt.Errorf("failed to get resource: %s (%d)", resp.Body, resp.StatusCode())
+	}
+
+	res := resp.JSON200.Data
+	return models.Resource{
+		Name:  res.Name,
+		Code:  res.Code,
+		Skill: res.Skill,
+		Level: res.Level,
+	}, nil
+}
+
 func (r *Runner) GetResourcesByDrop(ctx context.Context, drop string) (models.Resources, error) {
 	resp, err := r.Client.GetAllResourcesResourcesGetWithResponse(ctx, &client.GetAllResourcesResourcesGetParams{
 		Drop: &drop,
@@ -287,6 +510,9 @@ func (r *Runner) GetResourcesBySkill(ctx context.Context, skill client.ResourceS
 			Level:    res.Level,
 			Location: locations[0], // todo allow more locations
 		}
+		for _, d := range res.Drops {
+			resource.Drops = append(resource.Drops, d.Code)
+		}
 		resources = append(resources, resource)
 	}
 
@@ -296,3 +522,38 @@ func (r *Runner) GetResourcesBySkill(ctx context.Context, skill client.ResourceS
 
 	return resources, nil
 }
+
+// GetEvents returns the active game events, and the map location each has spawned on
+func (r *Runner) GetEvents(ctx context.Context) (models.Events, error) {
+	resp, err := r.Client.GetAllEventsEventsGetWithResponse(ctx, &client.GetAllEventsEventsGetParams{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch events: %w", err)
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return nil, fmt.Errorf("status failure (%d), message: %s", resp.StatusCode(), resp.Body)
+	}
+
+	var events models.Events
+	for _, e := range resp.JSON200.Data {
+		s, dataErr := e.Map.Content.AsMapContentSchema()
+		if dataErr != nil {
+			return nil, fmt.Errorf("failed to extract event map content schema: %w", dataErr)
+		}
+
+		events = append(events, models.Event{
+			Name: e.Name,
+			Location: models.Location{
+				Name: e.Map.Name,
+				Skin: e.Map.Skin,
+				Coords: models.Coords{
+					X: e.Map.X,
+					Y: e.Map.Y,
+				},
+				Code: s.Code,
+				Type: s.Type,
+			},
+			Expiration: e.Expiration,
+		})
+	}
+	return events, nil
+}
@@ -0,0 +1,324 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"text/tabwriter"
+	"time"
+
+	"github.com/promiseofcake/artifactsmmo-go-client/client"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/promiseofcake/artifactsmmo-engine/internal/actions"
+	"github.com/promiseofcake/artifactsmmo-engine/internal/engine"
+	"github.com/promiseofcake/artifactsmmo-engine/internal/models"
+)
+
+var (
+	bankCode    string
+	bankQty     int
+	bankType    string
+	bankSubtype string
+)
+
+// bankCmd groups the bank related commands
+var bankCmd = &cobra.Command{
+	Use:   "bank",
+	Short: "Interact with the bank",
+}
+
+// bankListCmd lists the contents of the bank
+var bankListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the items in your bank",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		r := cmd.Context().Value(runnerKey).(*actions.Runner)
+
+		items, err := r.GetBankItems(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("failed to get bank items: %w", err)
+		}
+
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "CODE\tNAME\tTYPE\tSUBTYPE\tLEVEL\tQUANTITY")
+		for _, i := range items {
+			if bankCode != "" && i.Code != bankCode {
+				continue
+			}
+
+			item, iErr := r.GetItem(cmd.Context(), i.Code)
+			if iErr != nil {
+				return fmt.Errorf("failed to get item: %w", iErr)
+			}
+
+			if bankType != "" && item.Type != bankType {
+				continue
+			}
+			if bankSubtype != "" && item.Subtype != bankSubtype {
+				continue
+			}
+
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%d\n", i.Code, item.Name, item.Type, item.Subtype, item.Level, i.Quantity)
+		}
+		return w.Flush()
+	},
+}
+
+// bankDepositCmd travels to the nearest bank and deposits an item
+var bankDepositCmd = &cobra.Command{
+	Use:   "deposit",
+	Short: "Travel to the nearest bank and deposit an item",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		character := viper.GetViper().GetString("character")
+		if character == "" {
+			return fmt.Errorf("you must specify a character")
+		}
+		if bankCode == "" || bankQty <= 0 {
+			return fmt.Errorf("you must specify an item code and quantity")
+		}
+		r := cmd.Context().Value(runnerKey).(*actions.Runner)
+
+		err := travelToBank(cmd, r, character)
+		if err != nil {
+			return err
+		}
+
+		resp, err := r.Deposit(cmd.Context(), character, bankCode, bankQty)
+		if err != nil {
+			slog.Error("failed to deposit", "error", err.Error())
+			return fmt.Errorf("failed to deposit: %w", err)
+		}
+
+		cooldown := resp.GetCooldownDuration()
+		slog.Info("deposit results",
+			"code", bankCode,
+			"quantity", bankQty,
+			"cooldown", cooldown,
+		)
+		time.Sleep(cooldown)
+		return nil
+	},
+}
+
+// bankWithdrawCmd travels to the nearest bank and withdraws an item
+var bankWithdrawCmd = &cobra.Command{
+	Use:   "withdraw",
+	Short: "Travel to the nearest bank and withdraw an item",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		character := viper.GetViper().GetString("character")
+		if character == "" {
+			return fmt.Errorf("you must specify a character")
+		}
+		if bankCode == "" || bankQty <= 0 {
+			return fmt.Errorf("you must specify an item code and quantity")
+		}
+		r := cmd.Context().Value(runnerKey).(*actions.Runner)
+
+		err := travelToBank(cmd, r, character)
+		if err != nil {
+			return err
+		}
+
+		resp, err := r.Withdraw(cmd.Context(), character, bankCode, bankQty)
+		if err != nil {
+			slog.Error("failed to withdraw", "error", err.Error())
+			return fmt.Errorf("failed to withdraw: %w", err)
+		}
+
+		cooldown := resp.GetCooldownDuration()
+		slog.Info("withdraw results",
+			"code", bankCode,
+			"quantity", bankQty,
+			"cooldown", cooldown,
+		)
+		time.Sleep(cooldown)
+		return nil
+	},
+}
+
+// bankDepositAllCmd travels to the nearest bank and deposits the whole inventory
+var bankDepositAllCmd = &cobra.Command{
+	Use:   "deposit-all",
+	Short: "Travel to the nearest bank and deposit your entire inventory",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		character := viper.GetViper().GetString("character")
+		if character == "" {
+			return fmt.Errorf("you must specify a character")
+		}
+		r := cmd.Context().Value(runnerKey).(*actions.Runner)
+
+		err := engine.DepositAll(cmd.Context(), r, character)
+		if err != nil {
+			return fmt.Errorf("failed to deposit all: %w", err)
+		}
+		return nil
+	},
+}
+
+// bankGoldCmd shows the gold held in the bank
+var bankGoldCmd = &cobra.Command{
+	Use:   "gold",
+	Short: "Show the gold held in your bank",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		r := cmd.Context().Value(runnerKey).(*actions.Runner)
+
+		gold, err := r.GetBankGold(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("failed to get bank gold: %w", err)
+		}
+
+		fmt.Fprintln(cmd.OutOrStdout(), gold)
+		return nil
+	},
+}
+
+// bankUsageCmd reports the bank's slot usage
+var bankUsageCmd = &cobra.Command{
+	Use:   "usage",
+	Short: "Show how many of your bank slots are used",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		r := cmd.Context().Value(runnerKey).(*actions.Runner)
+
+		bank, items, err := engine.BankUsage(cmd.Context(), r)
+		if err != nil {
+			return fmt.Errorf("failed to get bank usage: %w", err)
+		}
+
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "SLOTS\tUSED\tFREE\tEXPANSIONS\tNEXT EXPANSION COST\tGOLD")
+		fmt.Fprintf(w, "%d\t%d\t%d\t%d\t%d\t%d\n", bank.Slots, len(items), bank.FreeSlots(items), bank.Expansions, bank.NextExpansionCost, bank.Gold)
+		return w.Flush()
+	},
+}
+
+// bankDepositGoldCmd travels to the nearest bank and deposits gold
+var bankDepositGoldCmd = &cobra.Command{
+	Use:   "deposit-gold",
+	Short: "Travel to the nearest bank and deposit gold",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		character := viper.GetViper().GetString("character")
+		if character == "" {
+			return fmt.Errorf("you must specify a character")
+		}
+		if bankQty <= 0 {
+			return fmt.Errorf("you must specify a quantity")
+		}
+		r := cmd.Context().Value(runnerKey).(*actions.Runner)
+
+		err := travelToBank(cmd, r, character)
+		if err != nil {
+			return err
+		}
+
+		resp, err := r.DepositGold(cmd.Context(), character, bankQty)
+		if err != nil {
+			return fmt.Errorf("failed to deposit gold: %w", err)
+		}
+
+		cooldown := resp.GetCooldownDuration()
+		slog.Info("deposit gold results",
+			"quantity", bankQty,
+			"bank", resp.BankGold,
+			"cooldown", cooldown,
+		)
+		time.Sleep(cooldown)
+		return nil
+	},
+}
+
+// bankWithdrawGoldCmd travels to the nearest bank and withdraws gold
+var bankWithdrawGoldCmd = &cobra.Command{
+	Use:   "withdraw-gold",
+	Short: "Travel to the nearest bank and withdraw gold",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		character := viper.GetViper().GetString("character")
+		if character == "" {
+			return fmt.Errorf("you must specify a character")
+		}
+		if bankQty <= 0 {
+			return fmt.Errorf("you must specify a quantity")
+		}
+		r := cmd.Context().Value(runnerKey).(*actions.Runner)
+
+		err := travelToBank(cmd, r, character)
+		if err != nil {
+			return err
+		}
+
+		resp, err := r.WithdrawGold(cmd.Context(), character, bankQty)
+		if err != nil {
+			return fmt.Errorf("failed to withdraw gold: %w", err)
+		}
+
+		cooldown := resp.GetCooldownDuration()
+		slog.Info("withdraw gold results",
+			"quantity", bankQty,
+			"bank", resp.BankGold,
+			"cooldown", cooldown,
+		)
+		time.Sleep(cooldown)
+		return nil
+	},
+}
+
+// bankExpandCmd travels to the nearest bank and buys the next bank expansion
+var bankExpandCmd = &cobra.Command{
+	Use:   "expand",
+	Short: "Travel to the nearest bank and buy a bank expansion with the character's gold",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		character := viper.GetViper().GetString("character")
+		if character == "" {
+			return fmt.Errorf("you must specify a character")
+		}
+		r := cmd.Context().Value(runnerKey).(*actions.Runner)
+
+		err := travelToBank(cmd, r, character)
+		if err != nil {
+			return err
+		}
+
+		resp, err := r.BuyBankExpansion(cmd.Context(), character)
+		if err != nil {
+			return fmt.Errorf("failed to buy bank expansion: %w", err)
+		}
+
+		cooldown := resp.GetCooldownDuration()
+		slog.Info("bank expansion results",
+			"price", resp.Price,
+			"cooldown", cooldown,
+		)
+		time.Sleep(cooldown)
+		return nil
+	},
+}
+
+// travelToBank moves the character to the nearest bank
+func travelToBank(cmd *cobra.Command, r *actions.Runner, character string) error {
+	err := engine.Travel(cmd.Context(), r, character, models.Location{
+		Type: string(client.Bank),
+		Code: string(client.Bank),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to travel to bank: %w", err)
+	}
+	return nil
+}
+
+func init() {
+	bankListCmd.Flags().StringVar(&bankCode, "code", "", "Only list items with this code")
+	bankListCmd.Flags().StringVar(&bankType, "type", "", "Only list items of this type")
+	bankListCmd.Flags().StringVar(&bankSubtype, "subtype", "", "Only list items of this subtype")
+
+	bankDepositCmd.Flags().StringVar(&bankCode, "code", "", "The code of the item to deposit")
+	bankDepositCmd.Flags().IntVar(&bankQty, "qty", 0, "The quantity to deposit")
+
+	bankWithdrawCmd.Flags().StringVar(&bankCode, "code", "", "The code of the item to withdraw")
+	bankWithdrawCmd.Flags().IntVar(&bankQty, "qty", 0, "The quantity to withdraw")
+
+	bankDepositGoldCmd.Flags().IntVar(&bankQty, "qty", 0, "The quantity of gold to deposit")
+	bankWithdrawGoldCmd.Flags().IntVar(&bankQty, "qty", 0, "The quantity of gold to withdraw")
+
+	bankCmd.AddCommand(bankListCmd, bankDepositCmd, bankWithdrawCmd, bankDepositAllCmd, bankGoldCmd, bankUsageCmd, bankDepositGoldCmd, bankWithdrawGoldCmd, bankExpandCmd)
+	rootCmd.AddCommand(bankCmd)
+}
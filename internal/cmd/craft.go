@@ -2,53 +2,58 @@ package cmd
 
 import (
 	"fmt"
-	"log/slog"
-	"time"
 
 	"github.com/spf13/viper"
 
 	"github.com/spf13/cobra"
 
 	"github.com/promiseofcake/artifactsmmo-engine/internal/actions"
+	"github.com/promiseofcake/artifactsmmo-engine/internal/engine"
 )
 
 var (
 	craftCode string
 	craftQty  int
+	craftLoop loopOptions
 )
 
-// craftCmd represents the gather command
+// craftCmd represents the craft command
 var craftCmd = &cobra.Command{
 	Use:   "craft",
-	Short: "Start a craft loop in your current location",
+	Short: "Start a craft loop at the workshop for your item",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		character := viper.GetViper().GetString("character")
 		if character == "" {
 			return fmt.Errorf("you must specify a character")
 		}
+		if craftCode == "" {
+			return fmt.Errorf("you must specify an item code")
+		}
 		r := cmd.Context().Value(runnerKey).(*actions.Runner)
-		for {
-			slog.Info("about to craft", "code", craftCode, "quantity", craftQty)
-
-			resp, err := r.Craft(cmd.Context(), character, craftCode, craftQty)
-			if err != nil {
-				slog.Error("failed to craft", "error", err.Error())
-				return fmt.Errorf("failed to craft: %w", err)
-			}
-
-			cooldown := resp.GetCooldownDuration()
-			slog.Info("craft results",
-				"xp", resp.SkillInfo.Xp,
-				"items", resp.SkillInfo.Items,
-				"cooldown", cooldown,
-			)
-			time.Sleep(cooldown)
+
+		item, err := r.GetItem(cmd.Context(), craftCode)
+		if err != nil {
+			return fmt.Errorf("failed to get item: %w", err)
+		}
+		if item.Craft == nil {
+			return fmt.Errorf("item is not craftable: %s", craftCode)
+		}
+		cs, err := item.Craft.AsCraftSchema()
+		if err != nil {
+			return fmt.Errorf("failed to get item craft schema: %w", err)
+		}
+
+		err = engine.CraftUntil(cmd.Context(), r, character, craftCode, craftQty, craftLoop.bankWhenFull, craftLoop.stopCondition(string(*cs.Skill)))
+		if err != nil {
+			return fmt.Errorf("failed to craft: %w", err)
 		}
+		return nil
 	},
 }
 
 func init() {
 	craftCmd.Flags().StringVar(&craftCode, "code", "", "The code of your item to craft")
-	craftCmd.Flags().IntVar(&craftQty, "qty", 0, "The quantity to craft")
+	craftCmd.Flags().IntVar(&craftQty, "qty", 1, "The quantity to craft per action")
+	craftLoop.addFlags(craftCmd)
 	rootCmd.AddCommand(craftCmd)
 }
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/promiseofcake/artifactsmmo-engine/internal/actions"
+	"github.com/promiseofcake/artifactsmmo-engine/internal/config"
+	"github.com/promiseofcake/artifactsmmo-engine/internal/engine"
+)
+
+// engineCmd groups the game engine commands
+var engineCmd = &cobra.Command{
+	Use:   "engine",
+	Short: "Run the automated game engine",
+}
+
+// engineRunCmd runs the configured characters and orders until stopped
+var engineRunCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Run the configured characters and orders, reloading them when the config file changes",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg := cmd.Context().Value(configKey).(config.Config)
+		r := cmd.Context().Value(runnerKey).(*actions.Runner)
+
+		slog.Info("starting artifacts-mmo game engine")
+
+		s := engine.NewSupervisor(cmd.Context(), r)
+		err := applyConfig(cmd, r, s, cfg)
+		if err != nil {
+			return err
+		}
+
+		viper.OnConfigChange(func(e fsnotify.Event) {
+			slog.Info("config file changed, reloading", "file", e.Name)
+			reloaded, lErr := config.Load()
+			if lErr == nil {
+				lErr = applyConfig(cmd, r, s, reloaded)
+			}
+			if lErr != nil {
+				slog.Error("failed to reload config, keeping the previous config", "error", lErr)
+			}
+		})
+		viper.WatchConfig()
+
+		slog.Info("waiting for processes to complete")
+		return s.Wait()
+	},
+}
+
+// applyConfig validates the config and applies its characters and orders to the Supervisor
+func applyConfig(cmd *cobra.Command, r *actions.Runner, s *engine.Supervisor, cfg config.Config) error {
+	err := cfg.Validate()
+	if err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+	err = cfg.ValidateItems(cmd.Context(), r)
+	if err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+
+	characters := make(map[string]engine.CharacterConfig)
+	for _, c := range cfg.Characters {
+		characters[c.Name] = engine.CharacterConfig{
+			Actions:       cfg.CharacterActions(c),
+			Training:      c.Training,
+			Refine:        c.Refine,
+			RefineBy:      c.RefineBy,
+			Health:        c.Health,
+			Deposit:       c.Deposit,
+			Gold:          c.Gold,
+			Recycle:       c.Recycle,
+			CraftTraining: c.CraftTraining,
+			Events:        c.Events,
+			Routine:       c.Routine,
+		}
+	}
+
+	// characters are validated as they're set, so nothing is applied unless they're valid
+	err = s.SetCharacters(characters)
+	if err != nil {
+		return err
+	}
+	engine.SetBankPolicy(cfg.Bank)
+	s.SetOrders(cfg.Orders)
+	return nil
+}
+
+func init() {
+	engineCmd.AddCommand(engineRunCmd)
+	rootCmd.AddCommand(engineCmd)
+}
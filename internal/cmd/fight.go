@@ -2,44 +2,60 @@ package cmd
 
 import (
 	"fmt"
-	"log/slog"
-	"time"
 
+	"github.com/promiseofcake/artifactsmmo-go-client/client"
 	"github.com/spf13/viper"
 
 	"github.com/spf13/cobra"
 
 	"github.com/promiseofcake/artifactsmmo-engine/internal/actions"
+	"github.com/promiseofcake/artifactsmmo-engine/internal/engine"
+	"github.com/promiseofcake/artifactsmmo-engine/internal/models"
+)
+
+var (
+	fightMonster string
+	fightLoop    loopOptions
+	fightHealth  models.HealthPolicy
 )
 
 var fightCmd = &cobra.Command{
 	Use:   "fight",
-	Short: "fight something",
+	Short: "Start a fight loop at a monster, or your current location",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		character := viper.GetViper().GetString("character")
 		if character == "" {
 			return fmt.Errorf("you must specify a character")
 		}
 		r := cmd.Context().Value(runnerKey).(*actions.Runner)
-		for {
-			slog.Info("about to fight")
+		c, err := r.GetMyCharacterInfo(cmd.Context(), character)
+		if err != nil {
+			return fmt.Errorf("failed to get character: %w", err)
+		}
 
-			resp, err := r.Fight(cmd.Context(), character)
+		location := models.Location{Coords: c.GetPosition()}
+		if fightMonster != "" {
+			location, err = engine.FindNearest(cmd.Context(), r, character, models.Location{
+				Type: string(client.Monster),
+				Code: fightMonster,
+			})
 			if err != nil {
-				slog.Error("failed to fight", "error", err.Error())
-				return fmt.Errorf("failed to fight: %w", err)
+				return fmt.Errorf("failed to find monster: %w", err)
 			}
+		}
 
-			cooldown := resp.GetCooldownDuration()
-			slog.Info("fight results",
-				"results", resp.FightResponse,
-				"cooldown", cooldown,
-			)
-			time.Sleep(cooldown)
+		err = engine.FightUntil(cmd.Context(), r, character, location, fightLoop.bankWhenFull, fightHealth, fightLoop.stopCondition("combat"))
+		if err != nil {
+			return fmt.Errorf("failed to fight: %w", err)
 		}
+		return nil
 	},
 }
 
 func init() {
+	fightCmd.Flags().StringVar(&fightMonster, "monster", "", "The code of the monster to travel to and fight")
+	fightCmd.Flags().Float64Var(&fightHealth.MinRatio, "min-hp-ratio", models.DefaultMinHPRatio, "Heal before fighting when HP drops below this ratio of max HP")
+	fightCmd.Flags().BoolVar(&fightHealth.WithdrawFood, "withdraw-food", false, "Withdraw food from the bank to heal when none is carried")
+	fightLoop.addFlags(fightCmd)
 	rootCmd.AddCommand(fightCmd)
 }
@@ -2,20 +2,26 @@ package cmd
 
 import (
 	"fmt"
-	"log/slog"
-	"time"
 
+	"github.com/promiseofcake/artifactsmmo-go-client/client"
 	"github.com/spf13/viper"
 
 	"github.com/spf13/cobra"
 
 	"github.com/promiseofcake/artifactsmmo-engine/internal/actions"
+	"github.com/promiseofcake/artifactsmmo-engine/internal/engine"
+	"github.com/promiseofcake/artifactsmmo-engine/internal/models"
+)
+
+var (
+	gatherResource string
+	gatherLoop     loopOptions
 )
 
 // gatherCmd represents the gather command
 var gatherCmd = &cobra.Command{
 	Use:   "gather",
-	Short: "Start a gather loop in your current location",
+	Short: "Start a gather loop at a resource, or your current location",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		character := viper.GetViper().GetString("character")
 		if character == "" {
@@ -23,26 +29,40 @@ var gatherCmd = &cobra.Command{
 		}
 
 		r := cmd.Context().Value(runnerKey).(*actions.Runner)
-		for {
-			slog.Info("about to gather")
+		c, err := r.GetMyCharacterInfo(cmd.Context(), character)
+		if err != nil {
+			return fmt.Errorf("failed to get character: %w", err)
+		}
 
-			resp, err := r.Gather(cmd.Context(), character)
+		resource := models.Resource{
+			Location: models.Location{Coords: c.GetPosition()},
+		}
+		if gatherResource != "" {
+			resource, err = r.GetResource(cmd.Context(), gatherResource)
 			if err != nil {
-				slog.Error("failed to gather", "error", err.Error())
-				return fmt.Errorf("failed to gather: %w", err)
+				return fmt.Errorf("failed to get resource: %w", err)
 			}
+			resource.Location, err = engine.FindNearest(cmd.Context(), r, character, models.Location{
+				Type: string(client.Resource),
+				Code: gatherResource,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to find resource: %w", err)
+			}
+		} else if gatherLoop.untilLevel > 0 {
+			return fmt.Errorf("--until-level requires a --resource")
+		}
 
-			cooldown := resp.GetCooldownDuration()
-			slog.Info("gather results",
-				"xp", resp.SkillInfo.Xp,
-				"items", resp.SkillInfo.Items,
-				"cooldown", cooldown,
-			)
-			time.Sleep(cooldown)
+		err = engine.GatherUntil(cmd.Context(), r, character, resource, gatherLoop.bankWhenFull, gatherLoop.stopCondition(string(resource.Skill)))
+		if err != nil {
+			return fmt.Errorf("failed to gather: %w", err)
 		}
+		return nil
 	},
 }
 
 func init() {
+	gatherCmd.Flags().StringVar(&gatherResource, "resource", "", "The code of the resource to travel to and gather")
+	gatherLoop.addFlags(gatherCmd)
 	rootCmd.AddCommand(gatherCmd)
 }
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/promiseofcake/artifactsmmo-engine/internal/engine"
+	"github.com/promiseofcake/artifactsmmo-engine/internal/models"
+)
+
+// loopOptions are the flags shared by the commands which loop an action
+type loopOptions struct {
+	count        int
+	untilLevel   int
+	bankWhenFull bool
+}
+
+// addFlags registers the loop flags on the given command
+func (o *loopOptions) addFlags(cmd *cobra.Command) {
+	cmd.Flags().IntVar(&o.count, "count", 0, "Stop after this many actions (0 runs forever)")
+	cmd.Flags().IntVar(&o.untilLevel, "until-level", 0, "Stop once the relevant skill reaches this level (0 runs forever)")
+	cmd.Flags().BoolVar(&o.bankWhenFull, "bank-when-full", false, "Deposit at the nearest bank when the inventory is full, then resume")
+}
+
+// stopCondition returns an engine.StopCondition for the flags, measuring levels against the given skill
+func (o *loopOptions) stopCondition(skill string) engine.StopCondition {
+	return func(c models.Character, count int) bool {
+		if o.count > 0 && count >= o.count {
+			return true
+		}
+		if o.untilLevel > 0 && c.GetSkillLevel(skill) >= o.untilLevel {
+			return true
+		}
+		return false
+	}
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/promiseofcake/artifactsmmo-engine/internal/actions"
+	"github.com/promiseofcake/artifactsmmo-engine/internal/config"
+	"github.com/promiseofcake/artifactsmmo-engine/internal/engine"
+)
+
+// planCmd simulates the configured engine orders without performing any actions
+var planCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Dry-run the configured orders against current bank and character state",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		character := viper.GetViper().GetString("character")
+		if character == "" {
+			return fmt.Errorf("you must specify a character")
+		}
+
+		cfg := cmd.Context().Value(configKey).(config.Config)
+		err := cfg.Validate()
+		if err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+		if len(cfg.Orders) == 0 {
+			return fmt.Errorf("no orders configured")
+		}
+
+		r := cmd.Context().Value(runnerKey).(*actions.Runner)
+		c, err := r.GetMyCharacterInfo(cmd.Context(), character)
+		if err != nil {
+			return fmt.Errorf("failed to get character: %w", err)
+		}
+
+		plan, err := engine.PlanOrders(cmd.Context(), r, c, cfg.Orders)
+		if err != nil {
+			return fmt.Errorf("failed to plan orders: %w", err)
+		}
+
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "STEP\tQUANTITY\tTARGET\tACTIONS\tDURATION")
+		for _, s := range plan.Steps {
+			indent := strings.Repeat("  ", s.Depth)
+			fmt.Fprintf(w, "%s%s %s\t%d\t%s\t%d\t%s\n", indent, s.Action, s.Code, s.Quantity, s.Target, s.Actions, s.Duration.Round(time.Second))
+		}
+		err = w.Flush()
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "\ntotal: %d actions, estimated %s for %s\n", plan.Actions(), plan.Duration().Round(time.Second), character)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(planCmd)
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/promiseofcake/artifactsmmo-engine/internal/actions"
+	"github.com/promiseofcake/artifactsmmo-engine/internal/engine"
+	"github.com/promiseofcake/artifactsmmo-engine/internal/models"
+)
+
+var recipeQty int
+
+// recipeCmd prints the full crafting tree for an item
+var recipeCmd = &cobra.Command{
+	Use:   "recipe <item_code>",
+	Short: "Print the full crafting tree for an item",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		r := cmd.Context().Value(runnerKey).(*actions.Runner)
+
+		bank, err := r.GetBankItems(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("failed to get bank items: %w", err)
+		}
+
+		characters, err := r.GetMyCharacters(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("failed to get characters: %w", err)
+		}
+
+		recipe, err := engine.BuildRecipe(cmd.Context(), r, args[0], recipeQty, bank)
+		if err != nil {
+			return fmt.Errorf("failed to build recipe: %w", err)
+		}
+
+		w := cmd.OutOrStdout()
+		printRecipe(w, recipe, characters, 0)
+
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "raw materials:")
+		for _, m := range recipe.RawMaterials() {
+			fmt.Fprintf(w, "  %s x%d (bank: %d)\n", m.Code, m.Quantity, bank.Count(m.Code))
+		}
+		return nil
+	},
+}
+
+// printRecipe writes a recipe node, and all of its inputs, indented by depth
+func printRecipe(w io.Writer, recipe *models.Recipe, characters []models.Character, depth int) {
+	indent := strings.Repeat("  ", depth)
+	fmt.Fprintf(w, "%s%s x%d (bank: %d)", indent, recipe.Code, recipe.Quantity, recipe.Banked)
+
+	if recipe.IsRaw() {
+		var sources []string
+		for _, res := range recipe.Resources {
+			sources = append(sources, fmt.Sprintf("%s [%s %d]", res.Code, res.Skill, res.Level))
+		}
+		for _, mon := range recipe.Monsters {
+			sources = append(sources, fmt.Sprintf("%s [monster %d]", mon.Code, mon.Level))
+		}
+		fmt.Fprintf(w, " from: %s\n", strings.Join(sources, ", "))
+		return
+	}
+
+	var crafters []string
+	for _, c := range characters {
+		if recipe.CanCraft(c) {
+			crafters = append(crafters, c.Name)
+		}
+	}
+	if len(crafters) == 0 {
+		crafters = append(crafters, "none")
+	}
+	fmt.Fprintf(w, " [%s %d] crafters: %s\n", recipe.Skill, recipe.Level, strings.Join(crafters, ", "))
+
+	for _, input := range recipe.Inputs {
+		printRecipe(w, input, characters, depth+1)
+	}
+}
+
+func init() {
+	recipeCmd.Flags().IntVar(&recipeQty, "qty", 1, "The quantity to craft")
+	rootCmd.AddCommand(recipeCmd)
+}
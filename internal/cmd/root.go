@@ -4,35 +4,63 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"os"
+	"time"
 
+	"github.com/lmittmann/tint"
 	"github.com/promiseofcake/artifactsmmo-engine/internal/actions"
+	"github.com/promiseofcake/artifactsmmo-engine/internal/config"
+	"github.com/promiseofcake/artifactsmmo-engine/internal/engine"
+	"github.com/promiseofcake/artifactsmmo-engine/internal/logging"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 const (
 	runnerKey = "runner"
+	configKey = "config"
 )
 
 var (
 	cfgFile       string
 	characterName string
+	token         string
+	logLevel      int
 )
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use: "artifactsmmo-engine",
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-		token := viper.GetViper().GetString("token")
-		if token == "" {
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		if cfg.Token == "" {
 			return errors.New("token required")
 		}
-		r, err := actions.NewDefaultRunner(token)
+
+		slog.SetDefault(slog.New(
+			tint.NewHandler(os.Stdout, &tint.Options{
+				Level:      slog.Level(cfg.LogLevel),
+				TimeFormat: time.Kitchen,
+			}),
+		))
+
+		engine.SetBankPolicy(cfg.Bank)
+		for _, c := range cfg.Characters {
+			engine.SetDepositPolicy(c.Name, c.Deposit)
+			engine.SetGoldPolicy(c.Name, c.Gold)
+		}
+
+		r, err := actions.NewDefaultRunner(cfg.Token)
 		if err != nil {
 			return err
 		}
 		ctx := context.WithValue(cmd.Context(), runnerKey, r)
+		ctx = context.WithValue(ctx, configKey, cfg)
+		ctx = logging.ContextWithLogger(ctx, slog.With("character", viper.GetViper().GetString("character")))
 		cmd.SetContext(ctx)
 		return nil
 	},
@@ -47,36 +75,33 @@ func Execute() {
 	}
 }
 
+// ExecuteEngine runs the engine, any arguments are passed on to `engine run`.
+// This is called by the engine binary's main.main().
+func ExecuteEngine() {
+	rootCmd.SetArgs(append([]string{"engine", "run"}, os.Args[1:]...))
+	Execute()
+}
+
 func init() {
 	cobra.OnInitialize(initConfig)
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.artifactsmmo-engine.yaml)")
 	rootCmd.PersistentFlags().StringVar(&characterName, "character", "", "The name of your character")
+	rootCmd.PersistentFlags().StringVar(&token, "token", "", "API token")
+	rootCmd.PersistentFlags().IntVar(&logLevel, "log_level", int(slog.LevelInfo), "log level")
 	viper.BindPFlags(rootCmd.PersistentFlags())
 }
 
 // initConfig reads in config file and ENV variables if set.
 func initConfig() {
-	if cfgFile != "" {
-		// Use config file from the flag.
-		viper.SetConfigFile(cfgFile)
-	} else {
-		// Find home directory.
-		home, err := os.UserHomeDir()
-		cobra.CheckErr(err)
-
-		// Search config in home directory with name ".artifactsmmo-engine" (without extension).
-		viper.AddConfigPath(home)
-		viper.SetConfigType("yaml")
-		viper.SetConfigName(".artifactsmmo-engine")
+	err := config.Init(cfgFile)
+	if err == nil {
+		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
+		return
 	}
 
-	viper.SetEnvPrefix("mmo")
-	viper.BindEnv("token")
-	viper.BindEnv("character")
-	viper.AutomaticEnv() // read in environment variables that match
-
-	// If a config file is found, read it in.
-	if err := viper.ReadInConfig(); err == nil {
-		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
+	// a missing config file is fine, everything can come from flags or ENV variables
+	var notFound viper.ConfigFileNotFoundError
+	if !errors.As(err, &notFound) {
+		cobra.CheckErr(err)
 	}
 }
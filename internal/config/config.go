@@ -0,0 +1,288 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/viper"
+
+	"github.com/promiseofcake/artifactsmmo-engine/internal/actions"
+	"github.com/promiseofcake/artifactsmmo-engine/internal/engine"
+	"github.com/promiseofcake/artifactsmmo-engine/internal/models"
+)
+
+const (
+	envPrefix  = "mmo"
+	configName = ".artifactsmmo-engine"
+)
+
+// Config is the configuration shared by the cli and the engine
+type Config struct {
+	Token      string              `mapstructure:"token"`
+	LogLevel   int                 `mapstructure:"log_level"`
+	Characters []Character         `mapstructure:"characters"`
+	Orders     []models.Order      `mapstructure:"orders"`
+	Strategies map[string][]string `mapstructure:"strategies"`
+	Bank       models.BankPolicy   `mapstructure:"bank"`
+}
+
+// Character is the engine configuration for a single character, either a list
+// of actions or the name of a strategy (a shared list of actions) can be given
+type Character struct {
+	Name          string                `mapstructure:"name"`
+	Actions       []string              `mapstructure:"actions"`
+	Strategy      string                `mapstructure:"strategy"`
+	Training      models.SkillTraining  `mapstructure:"training"`
+	Refine        []string              `mapstructure:"refine"`
+	RefineBy      string                `mapstructure:"refine_by"`
+	Health        models.HealthPolicy   `mapstructure:"health"`
+	Deposit       models.DepositPolicy  `mapstructure:"deposit"`
+	Gold          models.GoldPolicy     `mapstructure:"gold"`
+	Recycle       models.RecyclePolicy  `mapstructure:"recycle"`
+	CraftTraining models.CraftTraining  `mapstructure:"train_crafting"`
+	Events        models.EventInterests `mapstructure:"events"`
+	Routine       models.Routine        `mapstructure:"routine"`
+}
+
+// Init points viper at the config file, or $HOME/.artifactsmmo-engine.yaml if not given,
+// binds the MMO_ environment variables and reads the config in
+func Init(cfgFile string) error {
+	if cfgFile != "" {
+		viper.SetConfigFile(cfgFile)
+	} else {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return err
+		}
+		viper.AddConfigPath(home)
+		viper.SetConfigType("yaml")
+		viper.SetConfigName(configName)
+	}
+
+	viper.SetEnvPrefix(envPrefix)
+	for _, key := range []string{"token", "character", "log_level"} {
+		err := viper.BindEnv(key)
+		if err != nil {
+			return fmt.Errorf("failed to bind env: %w", err)
+		}
+	}
+	viper.AutomaticEnv()
+
+	return viper.ReadInConfig()
+}
+
+// Load returns the current Config from viper, times are given in RFC3339
+func Load() (Config, error) {
+	var cfg Config
+	err := viper.Unmarshal(&cfg, viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+		mapstructure.StringToTimeDurationHookFunc(),
+		mapstructure.StringToSliceHookFunc(","),
+		// order deadlines
+		mapstructure.StringToTimeHookFunc(time.RFC3339),
+	)))
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+	return cfg, nil
+}
+
+// CharacterActions returns the actions for the given character, resolving their strategy
+func (c Config) CharacterActions(character Character) []string {
+	if character.Strategy != "" {
+		return c.Strategies[character.Strategy]
+	}
+	return character.Actions
+}
+
+// Validate checks the config for mistakes which can be found without the game API,
+// all problems are reported together
+func (c Config) Validate() error {
+	var errs []error
+
+	if c.Token == "" {
+		errs = append(errs, errors.New("token is required"))
+	}
+
+	for name, actions := range c.Strategies {
+		for _, a := range actions {
+			if !engine.IsOperation(a) {
+				errs = append(errs, fmt.Errorf("strategy %s: unknown action: %s", name, a))
+			}
+		}
+	}
+
+	if c.Bank.WarnBelow < 0 {
+		errs = append(errs, errors.New("bank warn_below must not be negative"))
+	}
+
+	names := make(map[string]bool)
+	for n, ch := range c.Characters {
+		if ch.Name == "" {
+			errs = append(errs, fmt.Errorf("character %d: name is required", n))
+			continue
+		}
+		if names[ch.Name] {
+			errs = append(errs, fmt.Errorf("character %s: configured more than once", ch.Name))
+		}
+		names[ch.Name] = true
+
+		switch {
+		case ch.Strategy != "" && len(ch.Actions) > 0:
+			errs = append(errs, fmt.Errorf("character %s: set either actions or a strategy, not both", ch.Name))
+		case ch.Strategy != "":
+			if _, ok := c.Strategies[ch.Strategy]; !ok {
+				errs = append(errs, fmt.Errorf("character %s: unknown strategy: %s", ch.Name, ch.Strategy))
+			}
+		case len(ch.Actions) == 0 && len(ch.Routine.Steps) == 0:
+			errs = append(errs, fmt.Errorf("character %s: no actions configured", ch.Name))
+		}
+
+		for _, a := range ch.Actions {
+			if !engine.IsOperation(a) {
+				errs = append(errs, fmt.Errorf("character %s: unknown action: %s", ch.Name, a))
+			}
+		}
+
+		errs = append(errs, validateTraining(ch.Name, ch.Training)...)
+		errs = append(errs, validateRoutine(ch.Name, ch.Routine)...)
+
+		for _, s := range ch.Refine {
+			if !slices.Contains(models.RefiningSkills, s) {
+				errs = append(errs, fmt.Errorf("character %s: unknown refining skill: %s", ch.Name, s))
+			}
+		}
+		if ch.Health.MinRatio < 0 || ch.Health.MinRatio > 1 {
+			errs = append(errs, fmt.Errorf("character %s: health min_hp_ratio must be between 0 and 1", ch.Name))
+		}
+		for code, qty := range ch.Deposit.Keep {
+			if qty < 0 {
+				errs = append(errs, fmt.Errorf("character %s: deposit keep quantity for %s must not be negative", ch.Name, code))
+			}
+		}
+		if ch.Gold.Keep < 0 || ch.Gold.ExpandBelow < 0 || ch.Gold.Reserve < 0 {
+			errs = append(errs, fmt.Errorf("character %s: gold keep, expand_below and reserve must not be negative", ch.Name))
+		}
+		for code, keep := range ch.Recycle.Items {
+			if keep < 0 {
+				errs = append(errs, fmt.Errorf("character %s: recycle keep quantity for %s must not be negative", ch.Name, code))
+			}
+		}
+		if !engine.IsRefineMetric(ch.RefineBy) {
+			errs = append(errs, fmt.Errorf("character %s: unknown refine metric: %s", ch.Name, ch.RefineBy))
+		}
+		if !engine.IsCraftOutput(ch.CraftTraining.Output) {
+			errs = append(errs, fmt.Errorf("character %s: unknown craft output: %s", ch.Name, ch.CraftTraining.Output))
+		}
+	}
+
+	items := make(map[string]bool)
+	for n, o := range c.Orders {
+		if o.Item.Code == "" {
+			errs = append(errs, fmt.Errorf("order %d: item code is required", n))
+		} else if items[o.Item.Code] {
+			errs = append(errs, fmt.Errorf("order %d (%s): item ordered more than once", n, o.Item.Code))
+		}
+		items[o.Item.Code] = true
+		if o.Item.Quantity <= 0 {
+			errs = append(errs, fmt.Errorf("order %d (%s): quantity must be positive", n, o.Item.Code))
+		}
+		if o.Concurrency <= 0 {
+			errs = append(errs, fmt.Errorf("order %d (%s): concurrency must be positive", n, o.Item.Code))
+		}
+		if !models.IsOrderAction(o.Action) {
+			errs = append(errs, fmt.Errorf("order %d (%s): unknown action: %s", n, o.Item.Code, o.Action))
+		}
+		if o.Threshold < 0 || o.Threshold > o.Item.Quantity {
+			errs = append(errs, fmt.Errorf("order %d (%s): threshold must be between 0 and the quantity", n, o.Item.Code))
+		}
+		if o.Threshold > 0 && !o.Maintain {
+			errs = append(errs, fmt.Errorf("order %d (%s): threshold is only used by maintain orders", n, o.Item.Code))
+		}
+		if o.Skill != "" && o.Skill != "combat" && !models.IsSkill(o.Skill) {
+			errs = append(errs, fmt.Errorf("order %d (%s): unknown skill: %s", n, o.Item.Code, o.Skill))
+		}
+		for _, name := range o.Characters {
+			if !names[name] {
+				errs = append(errs, fmt.Errorf("order %d (%s): unknown character: %s", n, o.Item.Code, name))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateTraining checks the skill training config for a character
+func validateTraining(name string, t models.SkillTraining) []error {
+	var errs []error
+
+	switch t.Strategy {
+	case "", models.TrainWeakest, models.TrainRoundRobin, models.TrainXPPerHour:
+	default:
+		errs = append(errs, fmt.Errorf("character %s: unknown training strategy: %s", name, t.Strategy))
+	}
+
+	for _, s := range t.Skills {
+		if !models.IsSkill(s) {
+			errs = append(errs, fmt.Errorf("character %s: unknown training skill: %s", name, s))
+		}
+	}
+	for s := range t.Targets {
+		if !models.IsSkill(s) {
+			errs = append(errs, fmt.Errorf("character %s: unknown training target skill: %s", name, s))
+		}
+	}
+	for s, w := range t.Weights {
+		if !models.IsSkill(s) {
+			errs = append(errs, fmt.Errorf("character %s: unknown training weight skill: %s", name, s))
+		}
+		if w < 0 {
+			errs = append(errs, fmt.Errorf("character %s: training weight for %s must not be negative", name, s))
+		}
+	}
+
+	return errs
+}
+
+// validateRoutine checks the routine steps for a character
+func validateRoutine(name string, r models.Routine) []error {
+	var errs []error
+
+	for n, s := range r.Steps {
+		if !engine.IsOperation(s.Action) {
+			errs = append(errs, fmt.Errorf("character %s: routine step %d: unknown action: %s", name, n, s.Action))
+		}
+		if s.Duration < 0 || s.Count < 0 || s.Level < 0 {
+			errs = append(errs, fmt.Errorf("character %s: routine step %d: duration, count and level must not be negative", name, n))
+		}
+		if s.Skill != "" && s.Skill != "combat" && !models.IsSkill(s.Skill) {
+			errs = append(errs, fmt.Errorf("character %s: routine step %d: unknown skill: %s", name, n, s.Skill))
+		}
+		if s.Skill != "" && s.Level == 0 {
+			errs = append(errs, fmt.Errorf("character %s: routine step %d: skill is only used with a level", name, n))
+		}
+		if s.Window != "" {
+			if _, _, err := models.ParseWindow(s.Window); err != nil {
+				errs = append(errs, fmt.Errorf("character %s: routine step %d: %w", name, n, err))
+			}
+		}
+	}
+
+	return errs
+}
+
+// ValidateItems checks that every order item code exists in the game
+func (c Config) ValidateItems(ctx context.Context, r *actions.Runner) error {
+	var errs []error
+	for n, o := range c.Orders {
+		_, err := r.GetItem(ctx, o.Item.Code)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("order %d: unknown item code %s: %w", n, o.Item.Code, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,197 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/promiseofcake/artifactsmmo-engine/internal/models"
+)
+
+func TestValidate(t *testing.T) {
+	valid := Config{
+		Token: "foo",
+		Characters: []Character{
+			{Name: "Milnor", Actions: []string{"forage", "refine"}},
+			{Name: "Bilnor", Strategy: "gatherer"},
+		},
+		Orders: []models.Order{
+			{Item: models.SimpleItem{Code: "copper_dagger", Quantity: 5}, Concurrency: 1},
+		},
+		Strategies: map[string][]string{
+			"gatherer": {"forage"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		mutate   func(c *Config)
+		expected []string
+	}{
+		{"valid", func(c *Config) {}, nil},
+		{
+			"missing token",
+			func(c *Config) { c.Token = "" },
+			[]string{"token is required"},
+		},
+		{
+			"bad bank policy",
+			func(c *Config) { c.Bank.WarnBelow = -1 },
+			[]string{"bank warn_below must not be negative"},
+		},
+		{
+			"unknown action",
+			func(c *Config) { c.Characters[0].Actions = []string{"forage", "dance"} },
+			[]string{"character Milnor: unknown action: dance"},
+		},
+		{
+			"unknown strategy",
+			func(c *Config) { c.Characters[1].Strategy = "miner" },
+			[]string{"character Bilnor: unknown strategy: miner"},
+		},
+		{
+			"duplicate character",
+			func(c *Config) { c.Characters[1] = Character{Name: "Milnor", Actions: []string{"forage"}} },
+			[]string{"character Milnor: configured more than once"},
+		},
+		{
+			"bad training",
+			func(c *Config) {
+				c.Characters[0].Training = models.SkillTraining{
+					Strategy: "fastest",
+					Skills:   []string{"mining", "dancing"},
+				}
+			},
+			[]string{
+				"character Milnor: unknown training strategy: fastest",
+				"character Milnor: unknown training skill: dancing",
+			},
+		},
+		{
+			"bad order eligibility",
+			func(c *Config) {
+				c.Orders[0].Threshold = 2
+				c.Orders[0].Skill = "dancing"
+				c.Orders[0].Characters = []string{"Milnor", "Wilnor"}
+				c.Orders[0].Action = "steal"
+			},
+			[]string{
+				"order 0 (copper_dagger): unknown action: steal",
+				"order 0 (copper_dagger): threshold is only used by maintain orders",
+				"order 0 (copper_dagger): unknown skill: dancing",
+				"order 0 (copper_dagger): unknown character: Wilnor",
+			},
+		},
+		{
+			"bad refine",
+			func(c *Config) {
+				c.Characters[0].Refine = []string{"cooking", "weaponcrafting"}
+				c.Characters[0].RefineBy = "fun"
+				c.Characters[0].Health.MinRatio = 1.5
+				c.Characters[0].Deposit.Keep = map[string]int{"cooked_chicken": -1}
+				c.Characters[0].Recycle.Items = map[string]int{"copper_dagger": -1}
+				c.Characters[0].CraftTraining.Output = "burn"
+				c.Characters[0].Gold.Reserve = -1
+			},
+			[]string{
+				"character Milnor: recycle keep quantity for copper_dagger must not be negative",
+				"character Milnor: deposit keep quantity for cooked_chicken must not be negative",
+				"character Milnor: health min_hp_ratio must be between 0 and 1",
+				"character Milnor: unknown refining skill: weaponcrafting",
+				"character Milnor: unknown refine metric: fun",
+				"character Milnor: unknown craft output: burn",
+				"character Milnor: gold keep, expand_below and reserve must not be negative",
+			},
+		},
+		{
+			"bad routine",
+			func(c *Config) {
+				c.Characters[0].Actions = nil
+				c.Characters[0].Routine = models.Routine{Steps: []models.RoutineStep{
+					{Action: "forage", Duration: 45 * time.Minute},
+					{Action: "dance", Count: -1},
+					{Action: "fight", Skill: "dancing", Window: "25:00-02:00"},
+				}}
+			},
+			[]string{
+				"character Milnor: routine step 1: unknown action: dance",
+				"character Milnor: routine step 1: duration, count and level must not be negative",
+				"character Milnor: routine step 2: unknown skill: dancing",
+				"character Milnor: routine step 2: skill is only used with a level",
+				`character Milnor: routine step 2: invalid window "25:00-02:00"`,
+			},
+		},
+		{
+			"bad order",
+			func(c *Config) { c.Orders[0].Item.Quantity = 0; c.Orders[0].Concurrency = 0 },
+			[]string{
+				"order 0 (copper_dagger): quantity must be positive",
+				"order 0 (copper_dagger): concurrency must be positive",
+			},
+		},
+		{
+			"duplicate order",
+			func(c *Config) { c.Orders = append(c.Orders, c.Orders[0]) },
+			[]string{"order 1 (copper_dagger): item ordered more than once"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid
+			cfg.Characters = append([]Character{}, valid.Characters...)
+			cfg.Orders = append([]models.Order{}, valid.Orders...)
+			tt.mutate(&cfg)
+
+			err := cfg.Validate()
+			if tt.expected == nil {
+				assert.NoError(t, err)
+				return
+			}
+			for _, e := range tt.expected {
+				assert.ErrorContains(t, err, e)
+			}
+		})
+	}
+}
+
+func TestCharacterActions(t *testing.T) {
+	cfg := Config{Strategies: map[string][]string{"gatherer": {"forage", "refine"}}}
+
+	assert.Equal(t, []string{"forage", "refine"}, cfg.CharacterActions(Character{Strategy: "gatherer"}))
+	assert.Equal(t, []string{"refine"}, cfg.CharacterActions(Character{Actions: []string{"refine"}}))
+}
+
+func TestLoadOrders(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+	viper.SetConfigType("yaml")
+	err := viper.ReadConfig(strings.NewReader(`
+orders:
+  - item:
+      code: copper
+      quantity: 100
+    concurrency: 2
+    priority: 5
+    deadline: 2026-10-20T10:00:00Z
+    maintain: true
+    threshold: 50
+    characters: [Milnor]
+`))
+	assert.NoError(t, err)
+
+	cfg, err := Load()
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Order{{
+		Item:        models.SimpleItem{Code: "copper", Quantity: 100},
+		Concurrency: 2,
+		Priority:    5,
+		Deadline:    time.Date(2026, 10, 20, 10, 0, 0, 0, time.UTC),
+		Maintain:    true,
+		Threshold:   50,
+		Characters:  []string{"Milnor"},
+	}}, cfg.Orders)
+}
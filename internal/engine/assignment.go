@@ -0,0 +1,244 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+
+	"github.com/promiseofcake/artifactsmmo-engine/internal/models"
+)
+
+// CharacterConfig is the engine configuration for a single character
+type CharacterConfig struct {
+	Actions  []string
+	Training models.SkillTraining
+	// Refine restricts the skills refined by the refine action, all are refined if empty
+	Refine []string
+	// RefineBy is the metric used to choose what to refine, xp if empty
+	RefineBy string
+	// Health is when and how the character heals before fighting
+	Health models.HealthPolicy
+	// Deposit is what the character keeps when depositing
+	Deposit models.DepositPolicy
+	// Gold is how the character manages gold on bank visits
+	Gold models.GoldPolicy
+	// Recycle is which surplus bank items the recycle action recycles
+	Recycle models.RecyclePolicy
+	// CraftTraining is what the train-crafting action does with the items it crafts
+	CraftTraining models.CraftTraining
+	// Events are the event monsters and resources the character attends
+	Events models.EventInterests
+	// Routine is the schedule the character follows before returning to their actions
+	Routine models.Routine
+}
+
+// Assignment holds what a character has been configured to do, it can be swapped
+// or stopped while the character is running, taking effect at the next checkpoint
+type Assignment struct {
+	mu        sync.RWMutex
+	cfg       CharacterConfig
+	stopped   bool
+	interrupt bool
+	skipped   map[string]bool
+	progress  routineProgress
+}
+
+// NewAssignment returns an Assignment for the given config
+func NewAssignment(cfg CharacterConfig) (*Assignment, error) {
+	a := &Assignment{}
+	err := a.Set(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Set replaces the character config, changed actions or routine preempt the character's current work
+func (a *Assignment) Set(cfg CharacterConfig) error {
+	err := cfg.Validate()
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	// only a change to what the character does preempts them, other settings apply as they're read
+	configured := len(a.cfg.Actions) > 0 || len(a.cfg.Routine.Steps) > 0
+	if configured && (!slices.Equal(a.cfg.Actions, cfg.Actions) || !reflect.DeepEqual(a.cfg.Routine, cfg.Routine)) {
+		a.interrupt = true
+	}
+	if !reflect.DeepEqual(a.cfg.Routine, cfg.Routine) {
+		a.progress = routineProgress{}
+	}
+	a.cfg = cfg
+	return nil
+}
+
+// Validate checks the character config can be run
+func (cfg CharacterConfig) Validate() error {
+	if len(cfg.Actions) == 0 && len(cfg.Routine.Steps) == 0 {
+		return errors.New("nothing to do for character")
+	}
+	for _, op := range cfg.Actions {
+		if !IsOperation(op) {
+			return fmt.Errorf("unknown action: %s", op)
+		}
+	}
+	for _, step := range cfg.Routine.Steps {
+		if !IsOperation(step.Action) {
+			return fmt.Errorf("unknown routine action: %s", step.Action)
+		}
+	}
+	for _, skill := range cfg.Refine {
+		if !slices.Contains(models.RefiningSkills, skill) {
+			return fmt.Errorf("unknown refining skill: %s", skill)
+		}
+	}
+	if !IsRefineMetric(cfg.RefineBy) {
+		return fmt.Errorf("unknown refine metric: %s", cfg.RefineBy)
+	}
+	if !IsCraftOutput(cfg.CraftTraining.Output) {
+		return fmt.Errorf("unknown craft output: %s", cfg.CraftTraining.Output)
+	}
+	return nil
+}
+
+// Actions returns the current action names
+func (a *Assignment) Actions() []string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.cfg.Actions
+}
+
+// Training returns the current skill training config
+func (a *Assignment) Training() models.SkillTraining {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.cfg.Training
+}
+
+// Refine returns the skills to refine
+func (a *Assignment) Refine() []string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if len(a.cfg.Refine) == 0 {
+		return models.RefiningSkills
+	}
+	return a.cfg.Refine
+}
+
+// RefineBy returns the metric used to choose what to refine
+func (a *Assignment) RefineBy() string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.cfg.RefineBy == "" {
+		return models.RefineByXP
+	}
+	return a.cfg.RefineBy
+}
+
+// Health returns the health policy
+func (a *Assignment) Health() models.HealthPolicy {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.cfg.Health
+}
+
+// Recycle returns the recycle policy
+func (a *Assignment) Recycle() models.RecyclePolicy {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.cfg.Recycle
+}
+
+// CraftTraining returns the crafting training config
+func (a *Assignment) CraftTraining() models.CraftTraining {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.cfg.CraftTraining
+}
+
+// Events returns the event interests
+func (a *Assignment) Events() models.EventInterests {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.cfg.Events
+}
+
+// Routine returns the routine
+func (a *Assignment) Routine() models.Routine {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.cfg.Routine
+}
+
+// IsRefineMetric determines if the given name is a known refine metric, empty is the default
+func IsRefineMetric(name string) bool {
+	switch name {
+	case "", models.RefineByXP, models.RefineByDemand, models.RefineByPrice:
+		return true
+	}
+	return false
+}
+
+// IsCraftOutput determines if the given name is a known craft training output, empty is the default
+func IsCraftOutput(name string) bool {
+	switch name {
+	case "", models.CraftOutputKeep, models.CraftOutputRecycle, models.CraftOutputSell:
+		return true
+	}
+	return false
+}
+
+// Stop signals the character to stop at the next checkpoint
+func (a *Assignment) Stop() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.stopped = true
+}
+
+// Preempt asks the character to leave their current work at the next checkpoint
+func (a *Assignment) Preempt() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.interrupt = true
+}
+
+// interrupted reports if the character has been preempted or stopped, clearing the preemption
+func (a *Assignment) interrupted() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	interrupt := a.interrupt || a.stopped
+	a.interrupt = false
+	return interrupt
+}
+
+// skipEvent records an event the character doesn't qualify for, so it doesn't preempt them again
+func (a *Assignment) skipEvent(e models.Event) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.skipped == nil {
+		a.skipped = make(map[string]bool)
+	}
+	a.skipped[eventKey(e)] = true
+}
+
+// skippedEvent determines if the character has skipped the event
+func (a *Assignment) skippedEvent(e models.Event) bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.skipped[eventKey(e)]
+}
+
+// operations returns the current action names and their Operations, and whether the character should stop
+func (a *Assignment) operations() ([]string, []Operation, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	var operations []Operation
+	for _, op := range a.cfg.Actions {
+		operations = append(operations, operationsByName[op])
+	}
+	return a.cfg.Actions, operations, a.stopped
+}
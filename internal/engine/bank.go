@@ -0,0 +1,200 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/promiseofcake/artifactsmmo-engine/internal/actions"
+	"github.com/promiseofcake/artifactsmmo-engine/internal/logging"
+	"github.com/promiseofcake/artifactsmmo-engine/internal/models"
+)
+
+// BankFull is returned when the bank has no slot for what the character would gather or deposit
+var BankFull = errors.New("bank is full")
+
+// bankPolicy is the fleet's policy for keeping room in the shared bank
+var bankPolicy = struct {
+	sync.Mutex
+	p models.BankPolicy
+}{}
+
+// bankRejected records the items the bank couldn't accept when last deposited, for lack of a slot.
+// An item is accepted again once it fits, and every item once a deposit leaves a slot free.
+var bankRejected = struct {
+	sync.Mutex
+	codes map[string]bool
+}{codes: make(map[string]bool)}
+
+// overflowKey marks a context already clearing the bank, so the deposits made while
+// clearing don't clear again
+type overflowKey struct{}
+
+// SetBankPolicy sets the policy used when deposits overflow the bank
+func SetBankPolicy(p models.BankPolicy) {
+	bankPolicy.Lock()
+	defer bankPolicy.Unlock()
+	bankPolicy.p = p
+}
+
+// getBankPolicy returns the bank policy, the zero policy never clears the bank
+func getBankPolicy() models.BankPolicy {
+	bankPolicy.Lock()
+	defer bankPolicy.Unlock()
+	return bankPolicy.p
+}
+
+// rejectedItems returns the items the bank couldn't accept when last deposited
+func rejectedItems() []string {
+	bankRejected.Lock()
+	defer bankRejected.Unlock()
+	codes := make([]string, 0, len(bankRejected.codes))
+	for code := range bankRejected.codes {
+		codes = append(codes, code)
+	}
+	slices.Sort(codes)
+	return codes
+}
+
+// recordRejections records which of the deposits the bank rejected, forgetting every earlier
+// rejection if the bank still has a slot free
+func recordRejections(deposits models.SimpleItems, rejected []string, free int) {
+	bankRejected.Lock()
+	defer bankRejected.Unlock()
+	if free > 0 {
+		clear(bankRejected.codes)
+	}
+	for _, d := range deposits {
+		delete(bankRejected.codes, d.Code)
+	}
+	for _, code := range rejected {
+		bankRejected.codes[code] = true
+	}
+}
+
+// BankUsage returns the bank details and the banked items, warning if free slots are low
+func BankUsage(ctx context.Context, r *actions.Runner) (models.Bank, models.SimpleItems, error) {
+	bank, err := r.GetBankDetails(ctx)
+	if err != nil {
+		return models.Bank{}, nil, fmt.Errorf("get bank details: %w", err)
+	}
+	items, err := r.GetBankItems(ctx)
+	if err != nil {
+		return models.Bank{}, nil, fmt.Errorf("get bank items: %w", err)
+	}
+
+	if free := bank.FreeSlots(items); free < getBankPolicy().WarnBelow {
+		logging.Get(ctx).Warn("bank is nearly full", "slots", bank.Slots, "free", free)
+	}
+	return bank, items, nil
+}
+
+// makeBankRoom checks the bank has a slot for every new item in the deposits, when it doesn't
+// the items which stack onto banked items are deposited, and junk is cleared from the bank by
+// the bank policy. The deposits which still need depositing, and fit, are returned along with the
+// items kept back for lack of a bank slot.
+func makeBankRoom(ctx context.Context, r *actions.Runner, character string, deposits models.SimpleItems) (models.SimpleItems, []string, error) {
+	l := logging.Get(ctx)
+
+	bank, items, err := BankUsage(ctx, r)
+	if err != nil {
+		return nil, nil, err
+	}
+	need := bank.NewSlots(items, deposits)
+	if free := bank.FreeSlots(items); need <= free {
+		recordRejections(deposits, nil, free-need)
+		return deposits, nil, nil
+	}
+
+	clear := getBankPolicy().Overflow(items, need-bank.FreeSlots(items))
+	if len(clear) > 0 && ctx.Value(overflowKey{}) == nil {
+		// empty what we can first, so the character has room to clear the bank
+		var stacked, remaining models.SimpleItems
+		for _, d := range deposits {
+			if items.Count(d.Code) > 0 {
+				stacked = append(stacked, d)
+			} else {
+				remaining = append(remaining, d)
+			}
+		}
+		err = depositItems(ctx, r, character, stacked)
+		if err != nil {
+			return nil, nil, err
+		}
+		deposits = remaining
+
+		err = clearBank(context.WithValue(ctx, overflowKey{}, true), r, character, clear)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to clear bank: %w", err)
+		}
+		bank, items, err = BankUsage(ctx, r)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// deposit what fits, new items take the remaining free slots
+	var fits models.SimpleItems
+	var kept []string
+	free := bank.FreeSlots(items)
+	for _, d := range deposits {
+		if items.Count(d.Code) == 0 {
+			if free == 0 {
+				l.Warn("no bank slot free, keeping item", "code", d.Code, "qty", d.Quantity)
+				kept = append(kept, d.Code)
+				continue
+			}
+			free--
+		}
+		fits = append(fits, d)
+	}
+	recordRejections(fits, kept, free)
+	return fits, kept, nil
+}
+
+// clearBank sells, recycles or deletes each of the banked items
+func clearBank(ctx context.Context, r *actions.Runner, character string, clear []models.Clearance) error {
+	l := logging.Get(ctx)
+	for _, c := range clear {
+		l.Info("clearing item from the bank", "action", c.Action, "code", c.Item.Code, "qty", c.Item.Quantity)
+
+		var err error
+		switch c.Action {
+		case models.OverflowSell:
+			err = SellItems(ctx, r, character, c.Item.Code, c.Item.Quantity)
+		case models.OverflowRecycle:
+			policy := models.RecyclePolicy{Items: map[string]int{c.Item.Code: 0}}
+			for err == nil {
+				err = Recycle(ctx, r, character, policy, nil)
+			}
+			if errors.Is(err, NoItemsToRecycle) {
+				err = nil
+			}
+		case models.OverflowDelete:
+			err = DeleteItems(ctx, r, character, c.Item.Code, c.Item.Quantity)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteItems withdraws the given quantity of an item from the bank in inventory sized batches,
+// and destroys it
+func DeleteItems(ctx context.Context, r *actions.Runner, character string, code string, qty int) error {
+	l := logging.Get(ctx)
+	return withdrawBatches(ctx, r, character, code, qty, func(n int) error {
+		resp, err := r.DeleteItem(ctx, character, code, n)
+		if err != nil {
+			return fmt.Errorf("failed to delete %s, %d: %w", code, n, err)
+		}
+		cooldown := resp.GetCooldownDuration()
+		l.Info("deleted item", "code", code, "qty", n, "cooldown", cooldown)
+		time.Sleep(cooldown)
+		return nil
+	})
+}
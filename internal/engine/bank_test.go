@@ -0,0 +1,24 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/promiseofcake/artifactsmmo-engine/internal/models"
+)
+
+func TestRecordRejections(t *testing.T) {
+	t.Cleanup(func() { recordRejections(nil, nil, 1) })
+
+	recordRejections(models.SimpleItems{{Code: "copper_ore", Quantity: 10}}, []string{"sap", "feather"}, 0)
+	assert.Equal(t, []string{"feather", "sap"}, rejectedItems())
+
+	// an item which fits is accepted again, the others stay rejected while no slot is free
+	recordRejections(models.SimpleItems{{Code: "sap", Quantity: 2}}, nil, 0)
+	assert.Equal(t, []string{"feather"}, rejectedItems())
+
+	// a free slot accepts every item
+	recordRejections(nil, nil, 1)
+	assert.Empty(t, rejectedItems())
+}
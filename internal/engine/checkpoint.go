@@ -0,0 +1,59 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/promiseofcake/artifactsmmo-engine/internal/logging"
+	"github.com/promiseofcake/artifactsmmo-engine/internal/models"
+)
+
+// Preempted is returned from a checkpoint when the scheduler takes the character for other work
+var Preempted = errors.New("preempted")
+
+// operationPriority is the priority of an operation, any order the character is eligible for preempts it
+const operationPriority = math.MinInt
+
+// checkpointKey holds the checkpoint func in a context
+type checkpointKey struct{}
+
+// Checkpoint is a safe point in an action loop, after an action's cooldown, where the character may
+// be preempted, given their live state from the action's response. Loops return the error to unwind,
+// and rebuild their state from the game when resumed. Without a scheduler, such as from the cli, it
+// never preempts.
+func Checkpoint(ctx context.Context, c models.Character) error {
+	if check, ok := ctx.Value(checkpointKey{}).(func(models.Character) error); ok {
+		return check(c)
+	}
+	return nil
+}
+
+// withCheckpoint returns a context whose checkpoints call check
+func withCheckpoint(ctx context.Context, check func(models.Character) error) context.Context {
+	return context.WithValue(ctx, checkpointKey{}, check)
+}
+
+// scheduler returns a checkpoint preempting the character's work of the given priority, for a
+// manual preemption, an event of interest, or an eligible order of a higher priority. It records
+// if it preempted, so the work can be resumed.
+func scheduler(ctx context.Context, a *Assignment, fleet *Coordinator, priority int, preempted *bool) func(models.Character) error {
+	l := logging.Get(ctx)
+	return func(c models.Character) error {
+		reason := ""
+		switch {
+		case a.interrupted():
+			reason = "manual"
+		case eventWaiting(ctx, a, fleet):
+			reason = "event"
+		case fleet.Waiting(c, priority, time.Now()):
+			reason = "order"
+		default:
+			return nil
+		}
+		l.Info("preempting character", "reason", reason)
+		*preempted = true
+		return Preempted
+	}
+}
@@ -0,0 +1,57 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/promiseofcake/artifactsmmo-go-client/client"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/promiseofcake/artifactsmmo-engine/internal/models"
+)
+
+func TestCheckpoint(t *testing.T) {
+	ctx := context.Background()
+
+	// without a scheduler, checkpoints never preempt
+	assert.NoError(t, Checkpoint(ctx, models.Character{}))
+
+	q := NewOrderQueue()
+	fleet := NewCoordinator(nil, q)
+	a, err := NewAssignment(CharacterConfig{Actions: []string{"forage"}})
+	assert.NoError(t, err)
+	c := models.Character{CharacterSchema: client.CharacterSchema{Name: "Milnor", Level: 5}}
+
+	var preempted bool
+	opCtx := withCheckpoint(ctx, scheduler(ctx, a, fleet, operationPriority, &preempted))
+	assert.NoError(t, Checkpoint(opCtx, c))
+	assert.False(t, preempted)
+
+	// a manual preemption is taken once
+	a.Preempt()
+	assert.ErrorIs(t, Checkpoint(opCtx, c), Preempted)
+	assert.True(t, preempted)
+	assert.NoError(t, Checkpoint(opCtx, c))
+
+	// changed actions preempt, the same actions with other settings changed don't
+	assert.NoError(t, a.Set(CharacterConfig{Actions: []string{"forage"}}))
+	assert.NoError(t, Checkpoint(opCtx, c))
+	assert.NoError(t, a.Set(CharacterConfig{Actions: []string{"forage"}, Refine: []string{"mining"}}))
+	assert.NoError(t, Checkpoint(opCtx, c))
+	assert.NoError(t, a.Set(CharacterConfig{Actions: []string{"forage", "refine"}}))
+	assert.ErrorIs(t, Checkpoint(opCtx, c), Preempted)
+
+	// any order the character is eligible for preempts an operation
+	q.Push(models.Order{Item: models.SimpleItem{Code: "copper", Quantity: 10}, Skill: "mining", Level: 10})
+	assert.NoError(t, Checkpoint(opCtx, c))
+	q.Push(models.Order{Item: models.SimpleItem{Code: "ash_wood", Quantity: 10}})
+	assert.ErrorIs(t, Checkpoint(opCtx, c), Preempted)
+
+	// only higher priority orders preempt an order
+	var orderPreempted bool
+	orderCtx := withCheckpoint(ctx, scheduler(ctx, a, fleet, 0, &orderPreempted))
+	assert.NoError(t, Checkpoint(orderCtx, c))
+	q.Push(models.Order{Item: models.SimpleItem{Code: "copper_ring", Quantity: 1}, Priority: 5})
+	assert.ErrorIs(t, Checkpoint(orderCtx, c), Preempted)
+	assert.True(t, orderPreempted)
+}
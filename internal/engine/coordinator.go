@@ -0,0 +1,392 @@
+package engine
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/promiseofcake/artifactsmmo-engine/internal/actions"
+	"github.com/promiseofcake/artifactsmmo-engine/internal/logging"
+	"github.com/promiseofcake/artifactsmmo-engine/internal/models"
+)
+
+// Coordinator assigns orders across the fleet of characters. It keeps the latest state of
+// every character, and plans which character takes each order across every character not
+// already on an order, so the best miner gathers ore while the best weaponcrafter crafts,
+// with materials handed off through the bank.
+type Coordinator struct {
+	r       *actions.Runner
+	queue   *OrderQueue
+	staging *Staging
+
+	mu         sync.Mutex
+	characters map[string]models.Character
+	skills     map[string]string
+	events     models.Events
+	polled     time.Time
+	skipped    map[string]time.Time
+	seen       map[string]time.Time
+	busy       map[string]bool
+	assigned   map[string]int
+	training   map[string]int
+}
+
+// eventPoll is how often the active events are fetched
+const eventPoll = time.Minute
+
+// skipWait is how long a character skips an order they couldn't fulfil
+const skipWait = 10 * time.Minute
+
+// staleAfter is how long since their last update a character is planned for, characters not
+// reaching checkpoints, such as those attending an event, aren't held orders
+const staleAfter = 5 * time.Minute
+
+// NewCoordinator returns a Coordinator assigning orders from the queue
+func NewCoordinator(r *actions.Runner, queue *OrderQueue) *Coordinator {
+	co := &Coordinator{
+		r:          r,
+		queue:      queue,
+		staging:    NewStaging(),
+		characters: make(map[string]models.Character),
+		skills:     make(map[string]string),
+		skipped:    make(map[string]time.Time),
+		seen:       make(map[string]time.Time),
+		busy:       make(map[string]bool),
+		assigned:   make(map[string]int),
+		training:   make(map[string]int),
+	}
+	queue.OnDrop(co.dropped)
+	return co
+}
+
+// Skip stops the character being assigned the order for a while, as their levels are too low for it
+func (co *Coordinator) Skip(c models.Character, o models.Order, now time.Time) {
+	co.mu.Lock()
+	defer co.mu.Unlock()
+	co.skipped[c.Name+"|"+o.Item.Code] = now.Add(skipWait)
+}
+
+// eligible determines if the order can be assigned to the character, and they haven't skipped
+// it. The caller holds the lock.
+func (co *Coordinator) eligible(c models.Character, o models.Order, now time.Time) bool {
+	return o.EligibleFor(c) && !now.Before(co.skipped[c.Name+"|"+o.Item.Code])
+}
+
+// Update records the latest state of a character
+func (co *Coordinator) Update(c models.Character) {
+	co.mu.Lock()
+	defer co.mu.Unlock()
+	co.characters[c.Name] = c
+	co.seen[c.Name] = time.Now()
+}
+
+// Remove forgets a character which has stopped
+func (co *Coordinator) Remove(name string) {
+	co.mu.Lock()
+	defer co.mu.Unlock()
+	delete(co.characters, name)
+	delete(co.seen, name)
+	delete(co.busy, name)
+	delete(co.assigned, name)
+}
+
+// Events returns the active game events, shared by the fleet and polled at most every eventPoll
+func (co *Coordinator) Events(ctx context.Context) (models.Events, error) {
+	co.mu.Lock()
+	defer co.mu.Unlock()
+	if time.Since(co.polled) < eventPoll {
+		return co.events, nil
+	}
+
+	events, err := co.r.GetEvents(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get events: %w", err)
+	}
+	co.events = events
+	co.polled = time.Now()
+	return events, nil
+}
+
+// Wait stages an order until its missing inputs are delivered, an order cancelled while it was
+// being fulfilled cancels its inputs instead
+func (co *Coordinator) Wait(o models.Order, inputs []models.Order) {
+	if co.queue.Cancelled(o) {
+		co.queue.CancelIDs([]int{o.ID})
+		return
+	}
+	co.staging.Stage(o, inputs)
+}
+
+// Cancel cancels the orders for the item, and the orders made for their inputs in turn, whether
+// queued or staged. Orders for the item made as inputs of other orders are kept.
+func (co *Coordinator) Cancel(code string) {
+	ids := co.queue.Cancel(code)
+	ids = append(ids, co.staging.Cancel(func(o models.Order) bool {
+		return o.For == 0 && o.Item.Code == code
+	})...)
+	for len(ids) > 0 {
+		cancelled := ids
+		ids = co.queue.CancelIDs(cancelled)
+		ids = append(ids, co.staging.Cancel(func(o models.Order) bool {
+			return slices.Contains(cancelled, o.ID) || slices.Contains(cancelled, o.For)
+		})...)
+	}
+}
+
+// OrderTraining queues an order for an input of crafting training, unless the last order for the
+// item is still outstanding, whether queued, staged or being fulfilled. It reports whether the
+// order was queued.
+func (co *Coordinator) OrderTraining(o models.Order) bool {
+	co.mu.Lock()
+	id, ok := co.training[o.Item.Code]
+	co.mu.Unlock()
+	if ok && co.outstanding(id) {
+		return false
+	}
+
+	id = co.queue.Push(o)
+	co.mu.Lock()
+	defer co.mu.Unlock()
+	co.training[o.Item.Code] = id
+	return id != 0
+}
+
+// outstanding determines if the order with the given ID is queued, staged or being fulfilled
+func (co *Coordinator) outstanding(id int) bool {
+	if co.queue.Queued(id) || co.staging.Staged(id) {
+		return true
+	}
+	co.mu.Lock()
+	defer co.mu.Unlock()
+	for _, assigned := range co.assigned {
+		if assigned == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Complete records a fulfilled order. Maintain orders are parked, and inputs are earmarked
+// for the order they were produced for, waking it once all of its inputs are delivered. An
+// input is only fulfilled by stock not earmarked for other orders, so its quantity was delivered.
+func (co *Coordinator) Complete(ctx context.Context, o models.Order) {
+	l := logging.Get(ctx)
+	co.staging.Release(o.ID)
+
+	if o.For != 0 {
+		if parent, ok := co.staging.Earmark(o.For, o.Item.Code, o.Item.Quantity); ok {
+			l.Debug("inputs delivered, waking order", "order", parent)
+			co.queue.Push(parent)
+		}
+	}
+
+	if o.Maintain {
+		l.Debug("order complete, maintaining stock", "order", o)
+		co.queue.Park(o)
+		return
+	}
+	l.Debug("order complete!", "order", o)
+}
+
+// dropped handles an order dropped from the queue, once expired or cancelled. Its earmarks are
+// released, and if it was an input the order waiting on it is released and queued again, to
+// order its inputs afresh.
+func (co *Coordinator) dropped(o models.Order) {
+	co.staging.Release(o.ID)
+	if o.For == 0 {
+		return
+	}
+	if parent, ok := co.staging.Unstage(o.For); ok {
+		slog.Info("input order dropped, re-queueing order", "order", parent, "input", o)
+		co.queue.Push(parent)
+	}
+}
+
+// Next assigns the idle character the order planned for them, if any. Orders go by priority,
+// then to the character best suited to them, then by deadline, so an order is held for a
+// better suited character, who leaves their operation for it at their next checkpoint.
+func (co *Coordinator) Next(ctx context.Context, c models.Character) (models.Order, bool) {
+	co.Update(c)
+	co.mu.Lock()
+	co.busy[c.Name] = false
+	delete(co.assigned, c.Name)
+	co.mu.Unlock()
+
+	skills := make(map[string]string)
+	for _, o := range co.queue.Orders() {
+		if _, ok := skills[o.Item.Code]; ok {
+			continue
+		}
+		skill, err := co.orderSkill(ctx, o)
+		if err != nil {
+			logging.Get(ctx).Warn("failed to determine order skill", "order", o, "error", err)
+		}
+		skills[o.Item.Code] = skill
+	}
+
+	now := time.Now()
+	planned, ok := co.plan(c, operationPriority, skills, now)
+	if !ok {
+		return models.Order{}, false
+	}
+	o, ok := co.queue.NextFunc(now, func(o models.Order) bool {
+		return o.ID == planned.ID
+	}, models.Order.Before)
+	if ok {
+		co.mu.Lock()
+		co.busy[c.Name] = true
+		co.assigned[c.Name] = o.ID
+		co.mu.Unlock()
+	}
+	return o, ok
+}
+
+// Waiting determines if an order of a higher priority than the given priority is planned
+// for the character
+func (co *Coordinator) Waiting(c models.Character, priority int, now time.Time) bool {
+	co.Update(c)
+
+	// only skills already known are used, so checkpoints don't call the game
+	skills := make(map[string]string)
+	co.mu.Lock()
+	for _, o := range co.queue.Orders() {
+		skills[o.Item.Code] = o.Skill
+		if skill, ok := co.skills[o.Item.Code]; ok && o.Skill == "" {
+			skills[o.Item.Code] = skill
+		}
+	}
+	co.mu.Unlock()
+
+	_, ok := co.plan(c, priority, skills, now)
+	return ok
+}
+
+// plan hands out the queued orders above the given priority to the characters not on an order,
+// and the given character, returning the order planned for them. The pairing picked first is
+// the highest priority order, with the character best suited to it relative to the fleet, then
+// the earliest deadline, so each character is left the order they're best at.
+func (co *Coordinator) plan(c models.Character, priority int, skills map[string]string, now time.Time) (models.Order, bool) {
+	var orders []models.Order
+	for _, o := range co.queue.Orders() {
+		if o.Priority > priority && !o.Expired(now) {
+			orders = append(orders, o)
+		}
+	}
+
+	co.mu.Lock()
+	defer co.mu.Unlock()
+
+	available := []models.Character{c}
+	for name, other := range co.characters {
+		if name != c.Name && !co.busy[name] && now.Sub(co.seen[name]) < staleAfter {
+			available = append(available, other)
+		}
+	}
+
+	for len(orders) > 0 && len(available) > 0 {
+		bestOrder, bestCharacter := -1, -1
+		var bestFitness int
+		for i, o := range orders {
+			for j, candidate := range available {
+				if !co.eligible(candidate, o, now) {
+					continue
+				}
+				f := co.fitness(candidate, skills[o.Item.Code])
+				if bestOrder >= 0 {
+					current := orders[bestOrder]
+					if o.Priority != current.Priority {
+						if o.Priority < current.Priority {
+							continue
+						}
+					} else if f != bestFitness {
+						if f < bestFitness {
+							continue
+						}
+					} else if !o.Before(current) {
+						// first in first out, and the given character first
+						continue
+					}
+				}
+				bestOrder, bestCharacter, bestFitness = i, j, f
+			}
+		}
+		if bestOrder < 0 {
+			return models.Order{}, false
+		}
+		if available[bestCharacter].Name == c.Name {
+			return orders[bestOrder], true
+		}
+		orders = slices.Delete(orders, bestOrder, bestOrder+1)
+		available = slices.Delete(available, bestCharacter, bestCharacter+1)
+	}
+	return models.Order{}, false
+}
+
+// fitness is the character's level in the skill less the best level in the fleet, so the
+// best character scores 0 and everyone else scores below. The caller holds the lock.
+func (co *Coordinator) fitness(c models.Character, skill string) int {
+	if skill == "" {
+		return 0
+	}
+
+	best := c.GetSkillLevel(skill)
+	for _, other := range co.characters {
+		best = max(best, other.GetSkillLevel(skill))
+	}
+	return c.GetSkillLevel(skill) - best
+}
+
+// orderSkill determines the skill used to fulfil an order, crafts use their craft skill,
+// gathers the resource skill and fights combat. Buying uses no skill.
+func (co *Coordinator) orderSkill(ctx context.Context, o models.Order) (string, error) {
+	if o.Skill != "" {
+		return o.Skill, nil
+	}
+
+	co.mu.Lock()
+	skill, ok := co.skills[o.Item.Code]
+	co.mu.Unlock()
+	if ok {
+		return skill, nil
+	}
+
+	item, err := co.r.GetItem(ctx, o.Item.Code)
+	if err != nil {
+		return "", fmt.Errorf("get item: %w", err)
+	}
+	source, err := OrderSource(ctx, co.r, o, item)
+	if err != nil {
+		return "", err
+	}
+
+	switch source {
+	case models.OrderCraft:
+		cs, csErr := item.Craft.AsCraftSchema()
+		if csErr != nil {
+			return "", fmt.Errorf("get item craft schema: %w", csErr)
+		}
+		skill = string(*cs.Skill)
+	case models.OrderGather:
+		resources, rErr := co.r.GetResourcesByDrop(ctx, o.Item.Code)
+		if rErr != nil {
+			return "", fmt.Errorf("get resources by drop: %w", rErr)
+		}
+		// the lowest level resource is the one most characters can gather
+		if len(resources) > 0 {
+			skill = string(slices.MinFunc(resources, func(a, b models.Resource) int {
+				return cmp.Compare(a.Level, b.Level)
+			}).Skill)
+		}
+	case models.OrderFight:
+		skill = "combat"
+	}
+
+	co.mu.Lock()
+	defer co.mu.Unlock()
+	co.skills[o.Item.Code] = skill
+	return skill, nil
+}
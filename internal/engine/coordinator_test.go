@@ -0,0 +1,159 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/promiseofcake/artifactsmmo-go-client/client"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/promiseofcake/artifactsmmo-engine/internal/models"
+)
+
+func TestCoordinatorNext(t *testing.T) {
+	q := NewOrderQueue()
+	q.Push(order("copper_dagger"))
+	q.Push(order("copper_ore"))
+	q.Push(order("copper_ore"))
+
+	co := NewCoordinator(nil, q)
+	co.skills["copper_dagger"] = "weaponcrafting"
+	co.skills["copper_ore"] = "mining"
+
+	miner := models.Character{CharacterSchema: client.CharacterSchema{Name: "Milnor", MiningLevel: 10, WeaponcraftingLevel: 1}}
+	smith := models.Character{CharacterSchema: client.CharacterSchema{Name: "Bilnor", MiningLevel: 2, WeaponcraftingLevel: 8}}
+	co.Update(miner)
+	co.Update(smith)
+
+	ctx := context.Background()
+	o, ok := co.Next(ctx, smith)
+	assert.True(t, ok)
+	assert.Equal(t, "copper_dagger", o.Item.Code)
+
+	o, ok = co.Next(ctx, miner)
+	assert.True(t, ok)
+	assert.Equal(t, "copper_ore", o.Item.Code)
+
+	// with only ore left the smith still helps rather than idling
+	o, ok = co.Next(ctx, smith)
+	assert.True(t, ok)
+	assert.Equal(t, "copper_ore", o.Item.Code)
+
+	// priority outranks fitness
+	q.Push(order("copper_dagger"))
+	urgent := order("copper_ore")
+	urgent.Priority = 1
+	q.Push(urgent)
+	o, ok = co.Next(ctx, smith)
+	assert.True(t, ok)
+	assert.Equal(t, "copper_ore", o.Item.Code)
+}
+
+func TestCoordinatorNextHoldsForBestFit(t *testing.T) {
+	q := NewOrderQueue()
+	q.Push(order("copper_ore"))
+
+	co := NewCoordinator(nil, q)
+	co.skills["copper_ore"] = "mining"
+
+	miner := models.Character{CharacterSchema: client.CharacterSchema{Name: "Milnor", MiningLevel: 10}}
+	smith := models.Character{CharacterSchema: client.CharacterSchema{Name: "Bilnor", MiningLevel: 2}}
+	co.Update(miner)
+
+	// the smith asks first, but the ore is held for the idle miner, who is preempted for it
+	ctx := context.Background()
+	_, ok := co.Next(ctx, smith)
+	assert.False(t, ok)
+	assert.False(t, co.Waiting(smith, operationPriority, time.Now()))
+	assert.True(t, co.Waiting(miner, operationPriority, time.Now()))
+
+	o, ok := co.Next(ctx, miner)
+	assert.True(t, ok)
+	assert.Equal(t, "copper_ore", o.Item.Code)
+
+	// once the miner is busy, the smith helps with the next copy rather than idling
+	q.Push(order("copper_ore"))
+	o, ok = co.Next(ctx, smith)
+	assert.True(t, ok)
+	assert.Equal(t, "copper_ore", o.Item.Code)
+
+	// a miner who has stopped reporting isn't held orders
+	co.Next(ctx, miner)
+	co.seen[miner.Name] = time.Now().Add(-staleAfter)
+	q.Push(order("copper_ore"))
+	_, ok = co.Next(ctx, smith)
+	assert.True(t, ok)
+}
+
+func TestCoordinatorSkip(t *testing.T) {
+	q := NewOrderQueue()
+	q.Push(order("copper_ore"))
+	co := NewCoordinator(nil, q)
+	co.skills["copper_ore"] = "mining"
+	c := models.Character{CharacterSchema: client.CharacterSchema{Name: "Milnor"}}
+	now := time.Now()
+
+	assert.True(t, co.Waiting(c, operationPriority, now))
+	co.Skip(c, order("copper_ore"), now)
+	assert.False(t, co.Waiting(c, operationPriority, now))
+	_, ok := co.Next(context.Background(), c)
+	assert.False(t, ok)
+
+	// the order is offered again once the skip expires
+	assert.True(t, co.Waiting(c, operationPriority, now.Add(skipWait)))
+}
+
+func TestCoordinatorSharedInput(t *testing.T) {
+	q := NewOrderQueue()
+	co := NewCoordinator(nil, q)
+
+	// two orders wait on the same input, each ordering their own
+	dagger := order("copper_dagger")
+	dagger.ID = 1
+	ring := order("copper_ring")
+	ring.ID = 2
+	daggerBars := models.Order{Item: models.SimpleItem{Code: "copper_bar", Quantity: 6}, For: dagger.ID}
+	ringBars := models.Order{Item: models.SimpleItem{Code: "copper_bar", Quantity: 6}, For: ring.ID}
+	co.Wait(dagger, []models.Order{daggerBars})
+	co.Wait(ring, []models.Order{ringBars})
+
+	// 6 bars are delivered for the dagger, waking it
+	assert.Equal(t, 6, availableFor(daggerBars, 6, co.staging))
+	co.Complete(context.Background(), daggerBars)
+	o, ok := q.Next(time.Now(), anyOrder)
+	assert.True(t, ok)
+	assert.Equal(t, dagger, o)
+
+	// the same bars don't count towards the ring, which stays staged
+	assert.Equal(t, 0, availableFor(ringBars, 6, co.staging))
+	assert.Equal(t, 6, co.staging.Earmarked("copper_bar"))
+	assert.Equal(t, 6, availableFor(ringBars, 12, co.staging))
+	co.Complete(context.Background(), ringBars)
+	o, ok = q.Next(time.Now(), anyOrder)
+	assert.True(t, ok)
+	assert.Equal(t, ring, o)
+	assert.Equal(t, 12, co.staging.Earmarked("copper_bar"))
+}
+
+func TestCoordinatorOrderTraining(t *testing.T) {
+	q := NewOrderQueue()
+	co := NewCoordinator(nil, q)
+	c := models.Character{CharacterSchema: client.CharacterSchema{Name: "Milnor"}}
+	ore := models.Order{Item: models.SimpleItem{Code: "copper_ore", Quantity: 30}, Concurrency: 1, Skill: "mining"}
+
+	// an input is ordered once while it's queued
+	assert.True(t, co.OrderTraining(ore))
+	assert.False(t, co.OrderTraining(ore))
+
+	// or while a character is fulfilling it
+	o, ok := co.Next(context.Background(), c)
+	assert.True(t, ok)
+	assert.False(t, co.OrderTraining(ore))
+
+	// once fulfilled it can be ordered again
+	co.Complete(context.Background(), o)
+	co.Remove(c.Name)
+	assert.True(t, co.OrderTraining(ore))
+	assert.Equal(t, 1, q.Len())
+}
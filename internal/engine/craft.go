@@ -0,0 +1,211 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/promiseofcake/artifactsmmo-go-client/client"
+
+	"github.com/promiseofcake/artifactsmmo-engine/internal/actions"
+	"github.com/promiseofcake/artifactsmmo-engine/internal/logging"
+	"github.com/promiseofcake/artifactsmmo-engine/internal/models"
+)
+
+// CraftUntil will move to the appropriate workshop, and craft loop the given item until the stop
+// condition is met. If bank is set the character restocks the materials for the next craft
+// from the bank whenever they run out, and deposits everything else.
+func CraftUntil(ctx context.Context, r *actions.Runner, character string, code string, qty int, bank bool, done StopCondition) error {
+	l := logging.Get(ctx)
+
+	item, err := r.GetItem(ctx, code)
+	if err != nil {
+		return fmt.Errorf("get item: %w", err)
+	}
+	if item.Craft == nil {
+		return fmt.Errorf("item is not craftable: %s", code)
+	}
+
+	cs, err := item.Craft.AsCraftSchema()
+	if err != nil {
+		return fmt.Errorf("get item craft schema: %w", err)
+	}
+	workshop := models.Location{
+		Code: string(*cs.Skill),
+		Type: string(client.Workshop),
+	}
+
+	c, err := r.GetMyCharacterInfo(ctx, character)
+	if err != nil {
+		return fmt.Errorf("get character info: %w", err)
+	}
+
+	err = Travel(ctx, r, character, workshop)
+	if err != nil {
+		return err
+	}
+
+	var count int
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+			if bank && !hasMaterials(c, *cs.Items, qty) {
+				l.Info("restocking materials", "code", code, "qty", qty)
+				dErr := DepositAll(ctx, r, character)
+				if dErr != nil {
+					return fmt.Errorf("failed to deposit all: %w", dErr)
+				}
+
+				for _, mat := range *cs.Items {
+					resp, wErr := r.Withdraw(ctx, character, mat.Code, mat.Quantity*qty)
+					if wErr != nil {
+						return fmt.Errorf("failed to restock materials: %w", wErr)
+					}
+					c.CharacterSchema = resp.CharacterResponse.CharacterSchema
+					time.Sleep(resp.GetCooldownDuration())
+				}
+
+				err = Travel(ctx, r, character, workshop)
+				if err != nil {
+					return err
+				}
+			}
+
+			resp, cErr := r.Craft(ctx, character, code, qty)
+			if cErr != nil {
+				return fmt.Errorf("failed to craft %s, %d, code: %w", code, qty, cErr)
+			}
+			count++
+			cooldown := resp.GetCooldownDuration()
+			l.Info("crafted item", "code", code, "result", resp.SkillInfo, "cooldown", cooldown)
+			skillHistory.Record(character, string(*cs.Skill), resp.SkillInfo.Xp, cooldown)
+			c.CharacterSchema = resp.CharacterResponse.CharacterSchema
+			time.Sleep(cooldown)
+
+			if done(c, count) {
+				return nil
+			}
+			if cErr := Checkpoint(ctx, c); cErr != nil {
+				return cErr
+			}
+		}
+	}
+}
+
+// hasMaterials determines if the character is carrying enough materials for qty crafts
+func hasMaterials(c models.Character, materials []client.SimpleItemSchema, qty int) bool {
+	for _, mat := range materials {
+		if c.CountInventoryItem(mat.Code) < mat.Quantity*qty {
+			return false
+		}
+	}
+	return true
+}
+
+// CraftBatched crafts qty of the given item from materials in the bank, splitting the crafts into
+// batches which fit in the character's inventory. Each batch deposits everything, withdraws the
+// batch's materials, crafts at the workshop and deposits the output. Materials earmarked for staged
+// orders are left alone, other than those for the order with the given ID.
+func CraftBatched(ctx context.Context, r *actions.Runner, character string, code string, qty int, staging *Staging, id int) error {
+	l := logging.Get(ctx)
+
+	item, err := r.GetItem(ctx, code)
+	if err != nil {
+		return fmt.Errorf("get item: %w", err)
+	}
+	if item.Craft == nil {
+		return fmt.Errorf("item is not craftable: %s", code)
+	}
+
+	cs, err := item.Craft.AsCraftSchema()
+	if err != nil {
+		return fmt.Errorf("get item craft schema: %w", err)
+	}
+	yield := 1
+	if cs.Quantity != nil {
+		yield = *cs.Quantity
+	}
+	var materials models.SimpleItems
+	for _, mat := range *cs.Items {
+		materials = append(materials, models.SimpleItem{Code: mat.Code, Quantity: mat.Quantity})
+	}
+
+	// each batch is a trip to the bank for materials, then to the workshop, choosing the bank
+	// on the way to the workshop rather than the nearest
+	stops := []models.Location{
+		{Code: string(client.Bank), Type: string(client.Bank)},
+		{Code: string(*cs.Skill), Type: string(client.Workshop)},
+	}
+	for remaining := qty; remaining > 0; {
+		var n int
+		err = TravelTrip(ctx, r, character, stops, true, func(i int, _ models.Location) error {
+			if i == 0 {
+				var wErr error
+				n, wErr = withdrawBatch(ctx, r, character, code, materials, yield, remaining, staging, id)
+				return wErr
+			}
+
+			resp, cErr := r.Craft(ctx, character, code, n)
+			if cErr != nil {
+				return fmt.Errorf("failed to craft %s, %d, code: %w", code, n, cErr)
+			}
+			cooldown := resp.GetCooldownDuration()
+			l.Info("crafted item", "code", code, "result", resp.SkillInfo, "cooldown", cooldown)
+			skillHistory.Record(character, string(*cs.Skill), resp.SkillInfo.Xp, cooldown)
+			time.Sleep(cooldown)
+			return Checkpoint(ctx, resp.CharacterResponse)
+		})
+		if err != nil {
+			return err
+		}
+
+		remaining -= n
+	}
+
+	return DepositAll(ctx, r, character)
+}
+
+// withdrawBatch deposits everything and withdraws the materials for the next batch of crafts, the
+// batch fits in the space left by the items the character keeps, and the materials not earmarked
+// for other orders. The batch size is returned.
+func withdrawBatch(ctx context.Context, r *actions.Runner, character string, code string, materials models.SimpleItems, yield int, remaining int, staging *Staging, id int) (int, error) {
+	l := logging.Get(ctx)
+
+	err := DepositAll(ctx, r, character)
+	if err != nil {
+		return 0, fmt.Errorf("failed to deposit all: %w", err)
+	}
+
+	c, err := r.GetMyCharacterInfo(ctx, character)
+	if err != nil {
+		return 0, fmt.Errorf("get character info: %w", err)
+	}
+	batch := c.MaxBatch(materials, yield)
+	if batch == 0 {
+		return 0, fmt.Errorf("inventory too small to craft: %s", code)
+	}
+	bank, err := r.GetBankItems(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("get bank items: %w", err)
+	}
+	n := min(batch, remaining)
+	for _, mat := range materials {
+		n = min(n, max(0, bank.Count(mat.Code)-staging.EarmarkedExcept(mat.Code, id))/mat.Quantity)
+	}
+	if n == 0 {
+		return 0, fmt.Errorf("not enough materials in the bank to craft: %s", code)
+	}
+	l.Info("crafting batch", "code", code, "qty", n, "remaining", remaining)
+
+	for _, mat := range materials {
+		l.Info("withdrawing item", "code", mat.Code, "qty", mat.Quantity*n)
+		resp, wErr := r.Withdraw(ctx, character, mat.Code, mat.Quantity*n)
+		if wErr != nil {
+			return 0, fmt.Errorf("failed to withdraw materials: %w", wErr)
+		}
+		time.Sleep(resp.GetCooldownDuration())
+	}
+	return n, nil
+}
@@ -4,7 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log/slog"
+	"slices"
+	"time"
 
 	"github.com/promiseofcake/artifactsmmo-engine/internal/actions"
 	"github.com/promiseofcake/artifactsmmo-engine/internal/logging"
@@ -13,85 +14,237 @@ import (
 
 // Operation is a type of event we want a character to do
 // ideally this is an event that is run until a stop value is returned
-type Operation func(ctx context.Context, r *actions.Runner, character models.Character) bool
+type Operation func(ctx context.Context, r *actions.Runner, character models.Character, a *Assignment, fleet *Coordinator) bool
 
-// Execute commands a character to focus on building their inventory
-// for harvestable items
-func Execute(ctx context.Context, r *actions.Runner, character string, actions []string, orders chan models.Order) error {
-	l := logging.Get(ctx)
+// idleWait is how long a character waits when an operation has nothing to do
+const idleWait = time.Minute
 
-	var operations []Operation
-	for _, op := range actions {
-		switch op {
-		case "gather":
-			// fallthrough
-		case "forage":
-			operations = append(operations, forage)
-		case "refine":
-			operations = append(operations, refine)
-		}
-	}
+// StopCondition reports if an action loop should stop, given the latest character
+// state and the number of actions performed so far
+type StopCondition func(c models.Character, count int) bool
 
-	if len(operations) == 0 {
-		slog.Error("nothing to do for character")
-		return errors.New("nothing to do for character")
-	}
+// operationsByName maps configured action names to their Operation
+var operationsByName = map[string]Operation{
+	"gather":         forage,
+	"forage":         forage,
+	"fight":          fight,
+	"refine":         refine,
+	"recycle":        recycle,
+	"train-crafting": trainCrafting,
+}
+
+// Preemption is a hook which may take over a character between operations, it reports whether
+// it ran so the character re-evaluates what to do afterwards
+type Preemption func(ctx context.Context, r *actions.Runner, c models.Character, a *Assignment, fleet *Coordinator) (bool, error)
+
+// preemptions are run in order before a character picks up an order or operation
+var preemptions = []Preemption{attendEvents}
+
+// IsOperation determines if the given action name maps to a known Operation
+func IsOperation(name string) bool {
+	_, ok := operationsByName[name]
+	return ok
+}
+
+// Execute commands a character to focus on building their inventory
+// for harvestable items, and fulfilling orders assigned by the fleet Coordinator.
+// A character with a routine works through its steps, stopping each at a checkpoint
+// once it's done, before returning to round-robin over their actions.
+// Orders and operations yield at checkpoints to higher priority work, a preempted
+// order is queued again and a preempted operation is resumed next.
+func Execute(ctx context.Context, r *actions.Runner, character string, a *Assignment, fleet *Coordinator) error {
+	l := logging.Get(ctx)
 
 	c, err := r.GetMyCharacterInfo(ctx, character)
 	if err != nil {
 		return fmt.Errorf("get character info: %w", err)
 	}
+	orders := fleet.queue
 
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	currentIndex := 1
+	var current []string
+	var currentIndex int
+	resume := false
 	for {
 		select {
 		case <-ctx.Done():
 			l.Debug("operation loop canceled.")
 			return nil
-		case o := <-orders:
+		default:
+		}
+
+		names, operations, stopped := a.operations()
+		if stopped {
+			l.Info("character stopped")
+			return nil
+		}
+		if !slices.Equal(names, current) {
+			// a changed list of actions starts from its first, rather than resuming an index into the old list
+			current = names
+			currentIndex = len(operations) - 1
+			resume = false
+		}
+		// between operations, a manual preemption is already honoured
+		a.interrupted()
+
+		err = reactivateOrders(ctx, r, orders)
+		if err != nil {
+			return fmt.Errorf("reactivate orders: %w", err)
+		}
+
+		// refresh the character, their levels determine which orders they are eligible for
+		c, err = r.GetMyCharacterInfo(ctx, character)
+		if err != nil {
+			return fmt.Errorf("get character info: %w", err)
+		}
+
+		if runPreemptions(ctx, r, c, a, fleet) {
+			continue
+		}
+
+		if o, ok := fleet.Next(ctx, c); ok {
 			l.Debug("attempting to fulfil order", "order", o)
-			if ShouldFulfilOrder(ctx, r, c, o) {
-				reqs, oErr := FulfilOrder(ctx, r, character, o)
-				if len(reqs) > 0 {
-					for _, req := range reqs {
-						orders <- req
-					}
-				}
+			if !ShouldFulfilOrder(ctx, r, c, o, fleet.staging) {
+				fleet.Complete(ctx, o)
+				continue
+			}
 
-				if oErr != nil {
-					l.Error("failed to fulfil order", "order", o, "error", oErr)
-					orders <- o
-					break
+			var preempted bool
+			orderCtx := withCheckpoint(ctx, scheduler(ctx, a, fleet, o.Priority, &preempted))
+			reqs, oErr := FulfilOrder(orderCtx, r, character, o, a.Health(), fleet.staging)
+			if len(reqs) > 0 {
+				for _, req := range reqs {
+					orders.Push(req)
 				}
+			}
 
-				if ShouldFulfilOrder(ctx, r, c, o) {
-					l.Debug("order incomplete, re-queueing", "order", o)
-					orders <- o
-				} else {
-					l.Debug("order complete!", "order", o)
-				}
+			if errors.Is(oErr, RequirementsNotMet) && len(reqs) == 0 {
+				// another character may be able to fulfil it
+				l.Info("character can't fulfil order, skipping", "order", o, "error", oErr)
+				fleet.Skip(c, o, time.Now())
+				orders.Push(o)
+				continue
 			}
-		default:
-			l.Debug("performing designated tasks", "tasks", operations)
-			currentIndex = (currentIndex + 1) % len(operations)
-			for !operations[currentIndex](ctx, r, c) {
+			if errors.Is(oErr, RequirementsNotMet) {
+				// the order is woken once its inputs are delivered
+				l.Debug("order waiting on inputs", "order", o, "inputs", reqs)
+				fleet.Wait(o, reqs)
+				continue
+			}
+			if preempted && errors.Is(oErr, Preempted) {
+				l.Debug("order preempted, re-queueing", "order", o)
+				orders.Push(o)
+				continue
+			}
+			if errors.Is(oErr, BankFull) {
+				l.Warn("bank is full, pausing order", "order", o, "duration", idleWait)
+				orders.Push(o)
+				time.Sleep(idleWait)
+				continue
+			}
+			if oErr != nil {
+				// errors such as a lack of gold persist, leave the order to others for a while
+				l.Error("failed to fulfil order, skipping", "order", o, "error", oErr)
+				fleet.Skip(c, o, time.Now())
+				orders.Push(o)
+				continue
+			}
+
+			if ShouldFulfilOrder(ctx, r, c, o, fleet.staging) {
+				l.Debug("order incomplete, re-queueing", "order", o)
+				orders.Push(o)
+			} else {
+				fleet.Complete(ctx, o)
+			}
+			continue
+		}
+
+		if step, ok := a.nextStep(c, time.Now()); ok {
+			if step == nil {
+				l.Info("no routine step in its window, idling", "duration", idleWait)
+				time.Sleep(idleWait)
+				continue
+			}
+			l.Debug("performing routine step", "action", step.Action)
+			var preempted bool
+			stepCtx := withCheckpoint(ctx, routineCheckpoint(ctx, a, scheduler(ctx, a, fleet, operationPriority, &preempted)))
+			operation := operationsByName[step.Action]
+			for !operation(stepCtx, r, c, a, fleet) {
 				select {
 				case <-ctx.Done():
-					l.Debug("engine canceled during processing.")
+					l.Debug("engine canceled during routine.")
 					return nil
 				default:
-					l.Debug("running operations")
+					l.Debug("running routine step")
 				}
 			}
+			continue
+		}
+
+		if len(operations) == 0 {
+			l.Info("routine finished with no actions, idling", "duration", idleWait)
+			time.Sleep(idleWait)
+			continue
+		}
+		l.Debug("performing designated tasks", "tasks", a.Actions())
+		if !resume || currentIndex >= len(operations) {
+			currentIndex = (currentIndex + 1) % len(operations)
+		}
+		var preempted bool
+		opCtx := withCheckpoint(ctx, scheduler(ctx, a, fleet, operationPriority, &preempted))
+		for !operations[currentIndex](opCtx, r, c, a, fleet) {
+			select {
+			case <-ctx.Done():
+				l.Debug("engine canceled during processing.")
+				return nil
+			default:
+				l.Debug("running operations")
+			}
+		}
+		resume = preempted
+	}
+}
+
+// runPreemptions runs the first preemption which takes over the character, a failed preemption is
+// logged and the character carries on
+func runPreemptions(ctx context.Context, r *actions.Runner, c models.Character, a *Assignment, fleet *Coordinator) bool {
+	for _, p := range preemptions {
+		ran, err := p(ctx, r, c, a, fleet)
+		if err != nil {
+			logging.Get(ctx).Error("failed to preempt character", "error", err)
+			continue
+		}
+		if ran {
+			return true
 		}
 	}
+	return false
+}
+
+// reactivateOrders queues parked maintain orders again once their bank stock drops
+func reactivateOrders(ctx context.Context, r *actions.Runner, orders *OrderQueue) error {
+	parked := orders.Parked()
+	if len(parked) == 0 {
+		return nil
+	}
+
+	bank, err := r.GetBankItems(ctx)
+	if err != nil {
+		return err
+	}
+	for _, o := range parked {
+		if o.Reactivates(bank.Count(o.Item.Code)) {
+			logging.Get(ctx).Info("stock below threshold, re-activating order", "order", o)
+			orders.Unpark(o.Item.Code)
+		}
+	}
+	return nil
 }
 
 // Operation loops
-func forage(ctx context.Context, r *actions.Runner, character models.Character) bool {
+func forage(ctx context.Context, r *actions.Runner, character models.Character, a *Assignment, _ *Coordinator) bool {
 	l := logging.Get(ctx)
 	for {
 		select {
@@ -100,7 +253,20 @@ func forage(ctx context.Context, r *actions.Runner, character models.Character)
 			return true
 		default:
 			l.Debug("foraging")
-			err := Forage(ctx, r, character.Name)
+			err := Forage(ctx, r, character.Name, a.Training())
+			if errors.Is(err, NoSkillsToTrain) {
+				l.Info("all gathering skills are trained, idling", "duration", idleWait)
+				time.Sleep(idleWait)
+				return true
+			}
+			if errors.Is(err, BankFull) {
+				l.Warn("bank is full, pausing gathering", "duration", idleWait)
+				time.Sleep(idleWait)
+				return true
+			}
+			if errors.Is(err, Preempted) {
+				return true
+			}
 			if err != nil {
 				panic(err)
 			}
@@ -110,7 +276,37 @@ func forage(ctx context.Context, r *actions.Runner, character models.Character)
 	}
 }
 
-func refine(ctx context.Context, r *actions.Runner, character models.Character) bool {
+func fight(ctx context.Context, r *actions.Runner, character models.Character, a *Assignment, _ *Coordinator) bool {
+	l := logging.Get(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			l.Debug("fight context closed")
+			return true
+		default:
+			l.Debug("fighting")
+			err := Fight(ctx, r, character.Name, a.Health())
+			if errors.Is(err, NoMonsters) {
+				l.Info("no monsters to fight, idling", "duration", idleWait)
+				time.Sleep(idleWait)
+				return true
+			}
+			if errors.Is(err, Preempted) {
+				return true
+			}
+			if err != nil {
+				// fights can be lost and requests fail, back off and try again
+				l.Error("failed to fight", "error", err, "duration", idleWait)
+				time.Sleep(idleWait)
+				return true
+			}
+			l.Debug("fighting done")
+			return true
+		}
+	}
+}
+
+func refine(ctx context.Context, r *actions.Runner, character models.Character, a *Assignment, fleet *Coordinator) bool {
 	l := logging.Get(ctx)
 	for {
 		select {
@@ -119,12 +315,88 @@ func refine(ctx context.Context, r *actions.Runner, character models.Character)
 			return true
 		default:
 			l.Debug("refining")
-			err := RefineAll(ctx, r, character.Name)
+			err := RefineAll(ctx, r, character.Name, a.Refine(), a.RefineBy(), fleet.queue.Demand(), fleet.staging)
+			if errors.Is(err, NoItemsToRefine) {
+				l.Info("no items to refine, idling", "duration", idleWait)
+				time.Sleep(idleWait)
+				return true
+			}
+			if errors.Is(err, Preempted) {
+				return true
+			}
 			if err != nil {
-				panic(err)
+				// a full bank or failed request leaves nothing to refine, back off and try again
+				l.Error("failed to refine", "error", err, "duration", idleWait)
+				time.Sleep(idleWait)
+				return true
 			}
 			l.Debug("refining done")
 			return true
 		}
 	}
 }
+
+func recycle(ctx context.Context, r *actions.Runner, character models.Character, a *Assignment, fleet *Coordinator) bool {
+	l := logging.Get(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			l.Debug("recycle context closed")
+			return true
+		default:
+			l.Debug("recycling")
+			err := Recycle(ctx, r, character.Name, a.Recycle(), fleet.staging)
+			if errors.Is(err, NoItemsToRecycle) {
+				l.Info("no surplus items to recycle, idling", "duration", idleWait)
+				time.Sleep(idleWait)
+				return true
+			}
+			if errors.Is(err, Preempted) {
+				return true
+			}
+			if err != nil {
+				// a full bank or failed request leaves nothing to recycle, back off and try again
+				l.Error("failed to recycle", "error", err, "duration", idleWait)
+				time.Sleep(idleWait)
+				return true
+			}
+			l.Debug("recycling done")
+			return true
+		}
+	}
+}
+
+func trainCrafting(ctx context.Context, r *actions.Runner, character models.Character, a *Assignment, fleet *Coordinator) bool {
+	l := logging.Get(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			l.Debug("train crafting context closed")
+			return true
+		default:
+			l.Debug("training crafting")
+			err := TrainCrafting(ctx, r, character.Name, a.Training(), a.CraftTraining(), fleet)
+			if errors.Is(err, NoSkillsToTrain) {
+				l.Info("all crafting skills are trained, idling", "duration", idleWait)
+				time.Sleep(idleWait)
+				return true
+			}
+			if errors.Is(err, InputsOrdered) {
+				l.Info("waiting on inputs for crafting, idling", "duration", idleWait)
+				time.Sleep(idleWait)
+				return true
+			}
+			if errors.Is(err, Preempted) {
+				return true
+			}
+			if err != nil {
+				// a full bank or failed request leaves nothing to craft, back off and try again
+				l.Error("failed to train crafting", "error", err, "duration", idleWait)
+				time.Sleep(idleWait)
+				return true
+			}
+			l.Debug("training crafting done")
+			return true
+		}
+	}
+}
@@ -2,6 +2,8 @@ package engine
 
 import (
 	"context"
+	"fmt"
+	"sync"
 	"time"
 
 	"github.com/promiseofcake/artifactsmmo-go-client/client"
@@ -11,40 +13,124 @@ import (
 	"github.com/promiseofcake/artifactsmmo-engine/internal/models"
 )
 
+// depositPolicies holds the deposit policy of every character
+var depositPolicies = struct {
+	sync.Mutex
+	m map[string]models.DepositPolicy
+}{m: make(map[string]models.DepositPolicy)}
+
+// SetDepositPolicy sets the policy used when the character deposits
+func SetDepositPolicy(character string, p models.DepositPolicy) {
+	depositPolicies.Lock()
+	defer depositPolicies.Unlock()
+	depositPolicies.m[character] = p
+}
+
+// getDepositPolicy returns the character's deposit policy, the zero policy deposits everything
+func getDepositPolicy(character string) models.DepositPolicy {
+	depositPolicies.Lock()
+	defer depositPolicies.Unlock()
+	return depositPolicies.m[character]
+}
+
 // DepositAll is an engine operation which commands a character
-// to visit a bank and deposit all of their inventory
+// to visit a bank and deposit all of their inventory, except for what their policy keeps.
+// Items the bank has no slot for are kept.
 func DepositAll(ctx context.Context, r *actions.Runner, character string) error {
+	_, err := deposit(ctx, r, character, func(p models.DepositPolicy, c models.Character) models.SimpleItems {
+		return p.Depositable(c)
+	})
+	return err
+}
+
+// MakeRoom commands a character to visit a bank and deposit enough to free the needed space,
+// a minimal policy deposits only that, otherwise it is the same as DepositAll. BankFull is
+// returned if the bank has no slot for the items which would free the space.
+func MakeRoom(ctx context.Context, r *actions.Runner, character string, need models.Space) error {
+	kept, err := deposit(ctx, r, character, func(p models.DepositPolicy, c models.Character) models.SimpleItems {
+		return p.PlanRoom(c, need)
+	})
+	if err != nil || len(kept) == 0 {
+		return err
+	}
+
+	c, err := r.GetMyCharacterInfo(ctx, character)
+	if err != nil {
+		return fmt.Errorf("get character info: %w", err)
+	}
+	if free := c.FreeSpace(); free.Items < need.Items || free.Slots < need.Slots {
+		return fmt.Errorf("no bank slot for %v: %w", kept, BankFull)
+	}
+	return nil
+}
+
+// bankingSpace is the space a gather or fight loop frees when it banks, half the inventory
+// so the character isn't straight back to the bank
+func bankingSpace(c models.Character) models.Space {
+	return models.Space{Items: c.InventoryMaxItems / 2, Slots: 1}
+}
+
+// deposit visits the bank and deposits the planned items, making room in the bank first.
+// The items kept back for lack of a bank slot are returned.
+func deposit(ctx context.Context, r *actions.Runner, character string, plan func(models.DepositPolicy, models.Character) models.SimpleItems) ([]string, error) {
 	l := logging.Get(ctx)
-	err := Travel(ctx, r, character, models.Location{
+	bank := models.Location{
 		Type: string(client.Bank),
 		Code: string(client.Bank),
-	})
+	}
+
+	err := Travel(ctx, r, character, bank)
 	if err != nil {
 		l.Error("failed to travel to bank", "error", err)
-		return err
+		return nil, err
 	}
 
 	// get all character info
 	c, err := r.GetMyCharacterInfo(ctx, character)
 	if err != nil {
 		l.Error("failed to get character", "error", err)
-		return err
+		return nil, err
 	}
 
-	// loop over the inventory and deposit all
-	for _, i := range *c.Inventory {
-		if i.Quantity > 0 && i.Code != "" {
-			b, bErr := r.Deposit(ctx, character, i.Code, i.Quantity)
-			if bErr != nil {
-				l.Error("failed to deposit", "error", bErr)
-				return bErr
-			}
-			cooldown := time.Until(b.CooldownSchema.Expiration)
-			l.Info("deposited item into bank", "item", b.Item, "qty", i.Quantity, "cooldown", cooldown)
-			time.Sleep(cooldown)
-		}
+	deposits, kept, err := makeBankRoom(ctx, r, character, plan(getDepositPolicy(character), c))
+	if err != nil {
+		l.Error("failed to make room in the bank", "error", err)
+		return nil, err
+	}
+
+	// clearing the bank may have left it
+	err = Travel(ctx, r, character, bank)
+	if err != nil {
+		l.Error("failed to travel to bank", "error", err)
+		return nil, err
+	}
+
+	err = depositItems(ctx, r, character, deposits)
+	if err != nil {
+		return nil, err
 	}
 	l.Debug("deposit finished")
 
+	// depositing items leaves the character's gold alone, so there's no need to refresh them
+	if !getGoldPolicy(character).Applies(c) {
+		return kept, nil
+	}
+	return kept, ManageGold(ctx, r, c)
+}
+
+// depositItems deposits each of the items, one deposit per item code, and assumes the character
+// is at the bank
+func depositItems(ctx context.Context, r *actions.Runner, character string, items models.SimpleItems) error {
+	l := logging.Get(ctx)
+	for _, i := range items {
+		b, bErr := r.Deposit(ctx, character, i.Code, i.Quantity)
+		if bErr != nil {
+			l.Error("failed to deposit", "error", bErr)
+			return bErr
+		}
+		cooldown := time.Until(b.CooldownSchema.Expiration)
+		l.Info("deposited item into bank", "item", b.Item, "qty", i.Quantity, "cooldown", cooldown)
+		time.Sleep(cooldown)
+	}
 	return nil
 }
@@ -0,0 +1,102 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/promiseofcake/artifactsmmo-engine/internal/actions"
+	"github.com/promiseofcake/artifactsmmo-engine/internal/logging"
+	"github.com/promiseofcake/artifactsmmo-engine/internal/models"
+)
+
+// attendEvents takes the character to an active event spawning a monster or resource they are
+// interested in, and qualify for, fighting or gathering there until the event expires
+func attendEvents(ctx context.Context, r *actions.Runner, c models.Character, a *Assignment, fleet *Coordinator) (bool, error) {
+	l := logging.Get(ctx)
+
+	interests := a.Events()
+	if len(interests.Monsters) == 0 && len(interests.Resources) == 0 {
+		return false, nil
+	}
+
+	events, err := fleet.Events(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	for _, e := range interests.Matching(events, time.Now()) {
+		if a.skippedEvent(e) {
+			continue
+		}
+		expired := func(models.Character, int) bool {
+			return e.Expired(time.Now())
+		}
+
+		switch e.Location.Type {
+		case "monster":
+			// fight monsters at or below the character's level
+			monsters, mErr := r.GetMonsters(ctx, 0, c.Level)
+			if mErr != nil {
+				return false, fmt.Errorf("get monsters: %w", mErr)
+			}
+			if !slices.ContainsFunc(monsters, func(m models.Monster) bool { return m.Code == e.Location.Code }) {
+				l.Debug("character does not qualify for event", "event", e.Name, "monster", e.Location.Code)
+				a.skipEvent(e)
+				continue
+			}
+
+			l.Info("attending event", "event", e.Name, "monster", e.Location.Code, "expiration", e.Expiration)
+			err = FightUntil(ctx, r, c.Name, e.Location, true, a.Health(), expired)
+			if err != nil {
+				return true, fmt.Errorf("failed to fight at event %s: %w", e.Name, err)
+			}
+			return true, nil
+		case "resource":
+			resource, rErr := r.GetResource(ctx, e.Location.Code)
+			if rErr != nil {
+				return false, fmt.Errorf("get resource: %w", rErr)
+			}
+			if c.GetSkillLevel(string(resource.Skill)) < resource.Level {
+				l.Debug("character does not qualify for event", "event", e.Name, "resource", e.Location.Code)
+				a.skipEvent(e)
+				continue
+			}
+			resource.Location = e.Location
+
+			l.Info("attending event", "event", e.Name, "resource", e.Location.Code, "expiration", e.Expiration)
+			err = GatherUntil(ctx, r, c.Name, resource, true, expired)
+			if err != nil {
+				return true, fmt.Errorf("failed to gather at event %s: %w", e.Name, err)
+			}
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// eventWaiting determines if an event of interest is active which the character hasn't skipped
+func eventWaiting(ctx context.Context, a *Assignment, fleet *Coordinator) bool {
+	interests := a.Events()
+	if len(interests.Monsters) == 0 && len(interests.Resources) == 0 {
+		return false
+	}
+
+	events, err := fleet.Events(ctx)
+	if err != nil {
+		logging.Get(ctx).Warn("failed to get events", "error", err)
+		return false
+	}
+	for _, e := range interests.Matching(events, time.Now()) {
+		if !a.skippedEvent(e) {
+			return true
+		}
+	}
+	return false
+}
+
+// eventKey identifies an occurrence of an event
+func eventKey(e models.Event) string {
+	return e.Location.Code + "|" + e.Expiration.String()
+}
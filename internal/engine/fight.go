@@ -2,6 +2,7 @@ package engine
 
 import (
 	"context"
+	"errors"
 	"math"
 	"time"
 
@@ -12,8 +13,12 @@ import (
 	"github.com/promiseofcake/artifactsmmo-go-client/client"
 )
 
-// Fight will attempt to find and fight appropriate monsters
-func Fight(ctx context.Context, r *actions.Runner, character string) error {
+// NoMonsters is returned when there are no monsters on the map for the character to fight
+var NoMonsters = errors.New("no monsters to fight")
+
+// Fight will attempt to find and fight appropriate monsters, depositing their inventory whenever
+// it fills, until a checkpoint stops the character
+func Fight(ctx context.Context, r *actions.Runner, character string, health models.HealthPolicy) error {
 	l := logging.Get(ctx)
 	c, err := r.GetMyCharacterInfo(ctx, character)
 	if err != nil {
@@ -39,31 +44,92 @@ func Fight(ctx context.Context, r *actions.Runner, character string) error {
 	mon := models.MonstersToMap(monsterInfo)
 	mon.FindMonsters(loc)
 
-	var monster models.Monster
-	// pick a random monster
+	var monster *models.Monster
+	// pick a random monster which is on the map
 	for _, m := range mon {
-		monster = *m
-		break
+		if m.Location.Code != "" {
+			monster = m
+			break
+		}
 	}
+	if monster == nil {
+		return NoMonsters
+	}
+
+	return FightUntil(ctx, r, character, monster.Location, true, health, func(models.Character, int) bool {
+		return false
+	})
+}
+
+// FightUntil will move to, and fight loop the monster at a given location until the stop
+// condition is met. If bank is set the character deposits their inventory whenever it fills,
+// and returns to the monster to continue fighting. The character heals by the health policy
+// whenever the last fight left them below it.
+func FightUntil(ctx context.Context, r *actions.Runner, character string, location models.Location, bank bool, health models.HealthPolicy, done StopCondition) error {
+	l := logging.Get(ctx)
 
-	err = Move(ctx, r, character, monster.GetCoords())
+	c, err := r.GetMyCharacterInfo(ctx, character)
 	if err != nil {
-		l.Error("failed to move to monster", "error", err)
+		l.Error("failed to get character", "error", err)
 		return err
 	}
 
+	var count int
+	// the character moves to the monster at the start, and whenever healing or banking took them away
+	away := true
 	for {
-		f, fErr := r.Fight(ctx, character)
-		if fErr != nil {
-			l.Error("failed to fight monster", "error", fErr)
-			return fErr
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+			if health.NeedsHeal(c) {
+				err = Heal(ctx, r, character, health)
+				if err != nil {
+					l.Error("failed to heal", "error", err)
+					return err
+				}
+				// healing may have visited the bank
+				away = true
+			}
+
+			if away {
+				err = Move(ctx, r, character, location.Coords)
+				if err != nil {
+					l.Error("failed to move to monster", "error", err)
+					return err
+				}
+				away = false
+			}
+
+			f, fErr := r.Fight(ctx, character)
+			if fErr != nil {
+				l.Error("failed to fight monster", "error", fErr)
+				return fErr
+			}
+			count++
+			fCooldown := time.Until(f.CooldownSchema.Expiration)
+			l.Debug("fight results",
+				"results", f.FightResponse,
+				"cooldown", fCooldown,
+			)
+			c = f.CharacterResponse
+			time.Sleep(fCooldown)
+
+			if bank && c.ShouldBank() {
+				l.Debug("character will bank")
+				dErr := MakeRoom(ctx, r, character, bankingSpace(c))
+				if dErr != nil {
+					return dErr
+				}
+				away = true
+			}
+
+			if done(c, count) {
+				return nil
+			}
+			if cErr := Checkpoint(ctx, c); cErr != nil {
+				return cErr
+			}
 		}
-		fCooldown := time.Until(f.CooldownSchema.Expiration)
-		l.Debug("fight results",
-			"results", f.FightResponse,
-			"cooldown", fCooldown,
-		)
-		c = f.CharacterResponse
-		time.Sleep(fCooldown)
 	}
 }
@@ -2,16 +2,31 @@ package engine
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
+	"slices"
 	"time"
 
+	"github.com/promiseofcake/artifactsmmo-go-client/client"
+
 	"github.com/promiseofcake/artifactsmmo-engine/internal/actions"
 	"github.com/promiseofcake/artifactsmmo-engine/internal/logging"
 	"github.com/promiseofcake/artifactsmmo-engine/internal/models"
 )
 
-// Forage will attempt to Forage resources until the character should bank
-func Forage(ctx context.Context, r *actions.Runner, character string) error {
+// NoSkillsToTrain is returned when every skill has reached its target level or the level cap
+var NoSkillsToTrain = errors.New("no skills to train")
+
+// skillHistory records the experience earned by every character
+var skillHistory = models.NewSkillHistory()
+
+// gatherHistory records the results of every character's gathers
+var gatherHistory = models.NewGatherHistory()
+
+// Forage will attempt to Forage resources until the character should bank, the
+// gathering skill to train is chosen by the training config
+func Forage(ctx context.Context, r *actions.Runner, character string, training models.SkillTraining) error {
 	l := logging.Get(ctx)
 	c, err := r.GetMyCharacterInfo(ctx, character)
 	if err != nil {
@@ -19,8 +34,20 @@ func Forage(ctx context.Context, r *actions.Runner, character string) error {
 		return err
 	}
 
-	skill := c.ChooseWeakestSkill()
-	resources, err := r.GetResourcesBySkill(ctx, skill.Code, skill.MinLevel, skill.CurrentLevel)
+	caps, err := skillLevelCaps(ctx, r, models.GatheringSkills)
+	if err != nil {
+		l.Error("failed to get skill level caps", "error", err)
+		return err
+	}
+
+	skill, ok := c.ChooseSkill(models.GatheringSkills, training, caps, skillHistory.Last(character), func(s string) (float64, bool) {
+		return skillHistory.XPPerHour(character, s)
+	})
+	if !ok {
+		return NoSkillsToTrain
+	}
+
+	resources, err := r.GetResourcesBySkill(ctx, client.ResourceSchemaSkill(skill.Code), skill.MinLevel, skill.CurrentLevel)
 	if err != nil {
 		l.Error("failed to get resources", "error", err)
 		return err
@@ -32,21 +59,114 @@ func Forage(ctx context.Context, r *actions.Runner, character string) error {
 		return err
 	}
 
-	// begin
-	resource := resources[0]
-	l.Debug("choosing to gather", "resource", resource)
+	// while the bank is full, don't gather resources which only drop items it can't accept, or low value items
+	if rejected := rejectedItems(); len(rejected) > 0 {
+		skip := append(slices.Clone(getBankPolicy().Pause), rejected...)
+		resources = slices.DeleteFunc(resources, func(res models.Resource) bool {
+			return res.OnlyDrops(skip)
+		})
+		if len(resources) == 0 {
+			return BankFull
+		}
+	}
+
+	banks, err := r.GetMapsByContentType(ctx, client.Bank)
+	if err != nil {
+		l.Error("failed to get bank locations", "error", err)
+		return err
+	}
+
+	// begin with the resource expected to earn the most xp per hour
+	var resource models.Resource
+	best := -1.0
+	for _, res := range resources {
+		bank, found := banks.Nearest(string(client.Bank), res.GetCoords())
+		if !found {
+			return fmt.Errorf("no bank found")
+		}
+		stats, known := gatherHistory.Get(character, res.Code)
+		score := scoreResource(c, res, bank.Coords, stats, known)
+		l.Debug("scored resource", "resource", res.Code, "xp_per_hour", score, "history", known)
+		if score > best {
+			best = score
+			resource = res
+		}
+	}
+	l.Debug("choosing to gather", "resource", resource, "strategy", training.Strategy, "xp_per_hour", best)
 
 	// check if we should bank straight away
 	if c.ShouldBank() {
 		l.Debug("character will bank")
-		return DepositAll(ctx, r, character)
+		return MakeRoom(ctx, r, character, bankingSpace(c))
 	}
 
 	return Gather(ctx, r, character, resource)
 }
 
-// Gather will move to, and gather loop a resource
+// scoreResource estimates the xp per hour of gathering a resource, over a full cycle of travelling
+// to it, gathering until the inventory should be banked and a round trip to the nearest bank.
+// Historical results are used when known, otherwise they are estimated.
+func scoreResource(c models.Character, res models.Resource, bank models.Coords, stats models.GatherStats, known bool) float64 {
+	xp := estimateGatherXP(c.GetSkillLevel(string(res.Skill)), res.Level)
+	items := 1.0
+	cooldown := gatherCooldown
+	if known && stats.Gathers > 0 {
+		n := float64(stats.Gathers)
+		xp = float64(stats.XP) / n
+		if stats.Items > 0 {
+			items = float64(stats.Items) / n
+		}
+		cooldown = stats.Cooldown / time.Duration(stats.Gathers)
+	}
+
+	// a character banks once their inventory is 90% full
+	capacity := float64(max(1, c.InventoryMaxItems*9/10))
+	gathers := math.Ceil(capacity / items)
+
+	elapsed := moveDuration(c.GetPosition(), res.GetCoords()) +
+		time.Duration(gathers)*cooldown +
+		2*moveDuration(res.GetCoords(), bank) + bankCooldown
+	if elapsed <= 0 {
+		return 0
+	}
+	return gathers * xp / elapsed.Hours()
+}
+
+// estimateGatherXP estimates the xp of a single gather without any history, it grows with the
+// resource level and falls off to nothing for resources 10 or more levels below the skill level
+func estimateGatherXP(skillLevel, resourceLevel int) float64 {
+	below := max(0, skillLevel-resourceLevel)
+	if below >= 10 {
+		return 0
+	}
+	return float64(resourceLevel+1) * float64(10-below) / 10
+}
+
+// skillLevelCaps returns the level cap for each of the skills, the highest level of the skill's
+// resources and items
+func skillLevelCaps(ctx context.Context, r *actions.Runner, skills []string) (map[string]int, error) {
+	caps := make(map[string]int)
+	for _, s := range skills {
+		level, err := r.GetSkillContentLevel(ctx, s)
+		if err != nil {
+			return nil, err
+		}
+		caps[s] = level
+	}
+	return caps, nil
+}
+
+// Gather will move to, and gather loop a resource until the character should bank
 func Gather(ctx context.Context, r *actions.Runner, character string, resource models.Resource) error {
+	return GatherUntil(ctx, r, character, resource, true, func(c models.Character, _ int) bool {
+		return c.ShouldBank()
+	})
+}
+
+// GatherUntil will move to, and gather loop a resource until the stop condition is met.
+// If bank is set the character deposits their inventory whenever it fills, and returns
+// to the resource to continue gathering.
+func GatherUntil(ctx context.Context, r *actions.Runner, character string, resource models.Resource, bank bool, done StopCondition) error {
 	l := logging.Get(ctx)
 
 	c, err := r.GetMyCharacterInfo(ctx, character)
@@ -62,6 +182,7 @@ func Gather(ctx context.Context, r *actions.Runner, character string, resource m
 	}
 
 	// harvest resource until we should stop
+	var count int
 	for {
 		select {
 		case <-ctx.Done():
@@ -72,14 +193,41 @@ func Gather(ctx context.Context, r *actions.Runner, character string, resource m
 				l.Error("failed to gather", "error", gErr)
 				return gErr
 			}
+			count++
 			cooldown := time.Until(g.CooldownSchema.Expiration)
 			l.Info("gathered resource", "resource", resource, "result", g.SkillInfo, "cooldown", cooldown)
+			skillHistory.Record(character, string(resource.Skill), g.SkillInfo.Xp, cooldown)
+			var items int
+			for _, i := range g.SkillInfo.Items {
+				items += i.Quantity
+			}
+			gatherHistory.Record(character, resource.Code, g.SkillInfo.Xp, items, cooldown)
 			c.CharacterSchema = g.CharacterResponse.CharacterSchema
 			time.Sleep(cooldown)
 
-			if c.ShouldBank() {
+			banked := false
+			if bank && c.ShouldBank() {
 				l.Debug("character will bank")
-				return DepositAll(ctx, r, character)
+				dErr := MakeRoom(ctx, r, character, bankingSpace(c))
+				if dErr != nil {
+					return dErr
+				}
+				banked = true
+			}
+
+			if done(c, count) {
+				return nil
+			}
+			if cErr := Checkpoint(ctx, c); cErr != nil {
+				return cErr
+			}
+
+			if banked {
+				mErr = Move(ctx, r, character, resource.GetCoords())
+				if mErr != nil {
+					l.Error("failed to move", "error", mErr)
+					return mErr
+				}
 			}
 		}
 	}
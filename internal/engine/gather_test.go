@@ -0,0 +1,59 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/promiseofcake/artifactsmmo-go-client/client"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/promiseofcake/artifactsmmo-engine/internal/models"
+)
+
+func resource(code string, level int, x, y int) models.Resource {
+	return models.Resource{
+		Code:     code,
+		Skill:    client.ResourceSchemaSkill("mining"),
+		Level:    level,
+		Location: models.Location{Coords: models.Coords{X: x, Y: y}},
+	}
+}
+
+func TestEstimateGatherXP(t *testing.T) {
+	tests := []struct {
+		name          string
+		skillLevel    int
+		resourceLevel int
+		expected      float64
+	}{
+		{"same level", 10, 10, 11},
+		{"five levels below", 15, 10, 5.5},
+		{"ten levels below", 20, 10, 0},
+		{"above the skill level", 1, 5, 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, estimateGatherXP(tt.skillLevel, tt.resourceLevel))
+		})
+	}
+}
+
+func TestScoreResource(t *testing.T) {
+	c := models.Character{CharacterSchema: client.CharacterSchema{MiningLevel: 10, InventoryMaxItems: 100}}
+	bank := models.Coords{X: 0, Y: 0}
+
+	near := resource("copper_rocks", 10, 1, 0)
+	far := resource("iron_rocks", 10, 10, 10)
+	low := resource("coal_rocks", 5, 1, 0)
+
+	assert.Greater(t, scoreResource(c, near, bank, models.GatherStats{}, false), scoreResource(c, far, bank, models.GatherStats{}, false))
+	assert.Greater(t, scoreResource(c, near, bank, models.GatherStats{}, false), scoreResource(c, low, bank, models.GatherStats{}, false))
+
+	// history overrides the estimate
+	stats := models.GatherStats{Gathers: 10, XP: 1000, Items: 10, Cooldown: 10 * gatherCooldown}
+	assert.Greater(t, scoreResource(c, far, bank, stats, true), scoreResource(c, near, bank, models.GatherStats{}, false))
+
+	// more items per gather fills the inventory sooner, leaving fewer gathers per trip
+	multi := models.GatherStats{Gathers: 10, XP: 100, Items: 30, Cooldown: 10 * gatherCooldown}
+	single := models.GatherStats{Gathers: 10, XP: 100, Items: 10, Cooldown: 10 * gatherCooldown}
+	assert.Less(t, scoreResource(c, far, bank, multi, true), scoreResource(c, far, bank, single, true))
+}
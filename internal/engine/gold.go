@@ -0,0 +1,90 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/promiseofcake/artifactsmmo-engine/internal/actions"
+	"github.com/promiseofcake/artifactsmmo-engine/internal/logging"
+	"github.com/promiseofcake/artifactsmmo-engine/internal/models"
+)
+
+// goldPolicies holds the gold policy of every character
+var goldPolicies = struct {
+	sync.Mutex
+	m map[string]models.GoldPolicy
+}{m: make(map[string]models.GoldPolicy)}
+
+// expansionMutex stops characters buying the same bank expansion at once
+var expansionMutex sync.Mutex
+
+// SetGoldPolicy sets the policy used when the character visits the bank
+func SetGoldPolicy(character string, p models.GoldPolicy) {
+	goldPolicies.Lock()
+	defer goldPolicies.Unlock()
+	goldPolicies.m[character] = p
+}
+
+// getGoldPolicy returns the character's gold policy, the zero policy leaves gold alone
+func getGoldPolicy(character string) models.GoldPolicy {
+	goldPolicies.Lock()
+	defer goldPolicies.Unlock()
+	return goldPolicies.m[character]
+}
+
+// ManageGold sweeps the character's gold into the bank, then buys a bank expansion if free slots
+// are low and the bank gold allows, by their gold policy. It assumes the character is at the bank.
+func ManageGold(ctx context.Context, r *actions.Runner, c models.Character) error {
+	l := logging.Get(ctx)
+	p := getGoldPolicy(c.Name)
+
+	if gold := p.Sweepable(c); gold > 0 {
+		resp, err := r.DepositGold(ctx, c.Name, gold)
+		if err != nil {
+			return fmt.Errorf("failed to deposit gold: %w", err)
+		}
+		cooldown := resp.GetCooldownDuration()
+		l.Info("deposited gold into bank", "qty", gold, "bank", resp.BankGold, "cooldown", cooldown)
+		c.CharacterSchema = resp.CharacterResponse.CharacterSchema
+		time.Sleep(cooldown)
+	}
+
+	if p.ExpandBelow <= 0 {
+		return nil
+	}
+
+	expansionMutex.Lock()
+	defer expansionMutex.Unlock()
+
+	bank, err := r.GetBankDetails(ctx)
+	if err != nil {
+		return fmt.Errorf("get bank details: %w", err)
+	}
+	items, err := r.GetBankItems(ctx)
+	if err != nil {
+		return fmt.Errorf("get bank items: %w", err)
+	}
+	if !p.ShouldExpand(bank, items) {
+		return nil
+	}
+
+	// expansions are paid for by the character, topped up from the bank
+	if missing := bank.NextExpansionCost - c.Gold; missing > 0 {
+		resp, wErr := r.WithdrawGold(ctx, c.Name, missing)
+		if wErr != nil {
+			return fmt.Errorf("failed to withdraw gold: %w", wErr)
+		}
+		time.Sleep(resp.GetCooldownDuration())
+	}
+
+	resp, err := r.BuyBankExpansion(ctx, c.Name)
+	if err != nil {
+		return fmt.Errorf("failed to buy bank expansion: %w", err)
+	}
+	cooldown := resp.GetCooldownDuration()
+	l.Info("bought bank expansion", "price", resp.Price, "free_slots", bank.FreeSlots(items), "cooldown", cooldown)
+	time.Sleep(cooldown)
+	return nil
+}
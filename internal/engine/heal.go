@@ -0,0 +1,116 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/promiseofcake/artifactsmmo-go-client/client"
+
+	"github.com/promiseofcake/artifactsmmo-engine/internal/actions"
+	"github.com/promiseofcake/artifactsmmo-engine/internal/logging"
+	"github.com/promiseofcake/artifactsmmo-engine/internal/models"
+)
+
+// Heal restores the character's HP when it is below the policy ratio, by eating food from
+// their inventory, or from the bank if the policy allows, and otherwise by resting
+func Heal(ctx context.Context, r *actions.Runner, character string, policy models.HealthPolicy) error {
+	l := logging.Get(ctx)
+	c, err := r.GetMyCharacterInfo(ctx, character)
+	if err != nil {
+		return fmt.Errorf("get character info: %w", err)
+	}
+	if !policy.NeedsHeal(c) {
+		return nil
+	}
+	l.Info("character needs healing", "hp", c.Hp, "max_hp", c.MaxHp)
+
+	var carried models.SimpleItems
+	for _, slot := range *c.Inventory {
+		if slot.Code != "" && slot.Quantity > 0 {
+			carried = append(carried, models.SimpleItem{Code: slot.Code, Quantity: slot.Quantity})
+		}
+	}
+	foods, err := findFood(ctx, r, carried)
+	if err != nil {
+		return err
+	}
+	meals := models.PlanMeals(foods, c.Level, c.MissingHP())
+
+	if len(meals) == 0 && policy.WithdrawFood {
+		meals, err = withdrawFood(ctx, r, c)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, meal := range meals {
+		resp, uErr := r.UseItem(ctx, character, meal.Code, meal.Quantity)
+		if uErr != nil {
+			return fmt.Errorf("failed to eat %s: %w", meal.Code, uErr)
+		}
+		cooldown := resp.GetCooldownDuration()
+		c = resp.CharacterResponse
+		l.Info("ate food", "code", meal.Code, "qty", meal.Quantity, "hp", c.Hp, "cooldown", cooldown)
+		time.Sleep(cooldown)
+	}
+
+	if policy.NeedsHeal(c) {
+		resp, rErr := r.Rest(ctx, character)
+		if rErr != nil {
+			return fmt.Errorf("failed to rest: %w", rErr)
+		}
+		cooldown := resp.GetCooldownDuration()
+		l.Info("rested", "hp_restored", resp.HpRestored, "cooldown", cooldown)
+		time.Sleep(cooldown)
+	}
+	return nil
+}
+
+// withdrawFood withdraws enough food from the bank to heal the character, and returns the meals
+func withdrawFood(ctx context.Context, r *actions.Runner, c models.Character) (models.SimpleItems, error) {
+	banked, err := r.GetBankItems(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get bank items: %w", err)
+	}
+	foods, err := findFood(ctx, r, banked)
+	if err != nil {
+		return nil, err
+	}
+	meals := models.PlanMeals(foods, c.Level, c.MissingHP())
+	if len(meals) == 0 {
+		return nil, nil
+	}
+
+	// a single stop, so the nearest bank is the shortest trip
+	err = Travel(ctx, r, c.Name, models.Location{
+		Type: string(client.Bank),
+		Code: string(client.Bank),
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, meal := range meals {
+		resp, wErr := r.Withdraw(ctx, c.Name, meal.Code, meal.Quantity)
+		if wErr != nil {
+			return nil, fmt.Errorf("failed to withdraw food: %w", wErr)
+		}
+		time.Sleep(resp.GetCooldownDuration())
+	}
+	return meals, nil
+}
+
+// findFood returns the items which are food
+func findFood(ctx context.Context, r *actions.Runner, items models.SimpleItems) ([]models.Food, error) {
+	var foods []models.Food
+	for _, i := range items {
+		item, err := r.GetItem(ctx, i.Code)
+		if err != nil {
+			return nil, fmt.Errorf("get item: %w", err)
+		}
+		if f, ok := models.FoodFromItem(item, i.Quantity); ok {
+			foods = append(foods, f)
+		}
+	}
+	return foods, nil
+}
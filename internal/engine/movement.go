@@ -3,7 +3,6 @@ package engine
 import (
 	"context"
 	"fmt"
-	"math"
 	"time"
 
 	"github.com/promiseofcake/artifactsmmo-go-client/client"
@@ -39,38 +38,97 @@ func Move(ctx context.Context, r *actions.Runner, character string, coords model
 
 // Travel facilitates travel to the nearest location for a given type/code
 func Travel(ctx context.Context, r *actions.Runner, character string, location models.Location) error {
+	l := logging.Get(ctx)
+	nearest, err := FindNearest(ctx, r, character, location)
+	if err != nil {
+		l.Error("failed to find location", "error", err)
+		return err
+	}
+
+	err = Move(ctx, r, character, nearest.Coords)
+	if err != nil {
+		l.Error("failed to move", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// FindNearest returns the nearest map location to the character for a given type/code
+func FindNearest(ctx context.Context, r *actions.Runner, character string, location models.Location) (models.Location, error) {
 	l := logging.Get(ctx)
 	maps, err := r.GetMapsByContentType(ctx, client.GetAllMapsMapsGetParamsContentType(location.Type))
 	if err != nil {
 		l.Error("failed to get maps", "error", err)
-		return err
+		return models.Location{}, err
 	}
 
 	c, err := r.GetMyCharacterInfo(ctx, character)
 	if err != nil {
-		return fmt.Errorf("failed to get character: %w", err)
+		return models.Location{}, fmt.Errorf("failed to get character: %w", err)
 	}
 
-	coords := models.Coords{}
+	nearest, found := maps.Nearest(location.Code, c.GetPosition())
+	if !found {
+		return models.Location{}, fmt.Errorf("no location found for %s: %s", location.Type, location.Code)
+	}
+	l.Debug("location found", "type", location.Type, "code", location.Code, "coords", nearest.Coords)
 
-	distance := math.MaxInt
-	for _, m := range maps {
-		if m.Code == location.Code {
-			temp := models.CalculateDistance(m.Coords, models.Coords{X: c.X, Y: c.Y})
-			if temp < distance {
-				distance = temp
-				coords.X = m.Coords.X
-				coords.Y = m.Coords.Y
+	return nearest, nil
+}
+
+// PlanTrip resolves each stop, given by type/code, to a concrete map tile and unless ordered
+// chooses the order to visit them in, so the character moves the least in total
+func PlanTrip(ctx context.Context, r *actions.Runner, character string, stops []models.Location, ordered bool) ([]models.TripStop, error) {
+	l := logging.Get(ctx)
+
+	maps := make(map[string]models.Locations)
+	candidates := make([]models.Locations, len(stops))
+	for i, stop := range stops {
+		if _, ok := maps[stop.Type]; !ok {
+			locs, err := r.GetMapsByContentType(ctx, client.GetAllMapsMapsGetParamsContentType(stop.Type))
+			if err != nil {
+				return nil, fmt.Errorf("failed to get maps: %w", err)
+			}
+			maps[stop.Type] = locs
+		}
+		for _, loc := range maps[stop.Type] {
+			if loc.Code == stop.Code {
+				candidates[i] = append(candidates[i], loc)
 			}
 		}
+		if len(candidates[i]) == 0 {
+			return nil, fmt.Errorf("no location found for %s: %s", stop.Type, stop.Code)
+		}
 	}
-	l.Debug("location found", "type", location.Type, "code", location.Code, "coords", coords)
 
-	err = Move(ctx, r, character, coords)
+	c, err := r.GetMyCharacterInfo(ctx, character)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get character: %w", err)
+	}
+
+	trip, distance := models.PlanTrip(c.GetPosition(), candidates, ordered)
+	l.Debug("planned trip", "stops", trip, "distance", distance)
+	return trip, nil
+}
+
+// TravelTrip plans a trip to the stops and moves to each in turn, calling visit at each stop
+// with the index of the stop as given
+func TravelTrip(ctx context.Context, r *actions.Runner, character string, stops []models.Location, ordered bool, visit func(i int, stop models.Location) error) error {
+	trip, err := PlanTrip(ctx, r, character, stops, ordered)
 	if err != nil {
-		l.Error("failed to move", "error", err)
 		return err
 	}
 
+	for _, stop := range trip {
+		err = Move(ctx, r, character, stop.Location.Coords)
+		if err != nil {
+			return err
+		}
+		err = visit(stop.Index, stop.Location)
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
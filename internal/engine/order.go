@@ -1,19 +1,28 @@
 package engine
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"time"
 
+	"github.com/promiseofcake/artifactsmmo-go-client/client"
+
 	"github.com/promiseofcake/artifactsmmo-engine/internal/actions"
 	"github.com/promiseofcake/artifactsmmo-engine/internal/logging"
 	"github.com/promiseofcake/artifactsmmo-engine/internal/models"
 )
 
+// RequirementsNotMet is returned with orders for the missing inputs of a craft, or without any
+// when the character's levels are too low to fulfil the order
+var RequirementsNotMet = errors.New("requirements not met")
+
 // ShouldFulfilOrder determines if this order is still relevant / should be fulfilled
-// it's based upon the quantity on hand in bank, not counting items in flight
-func ShouldFulfilOrder(ctx context.Context, r *actions.Runner, c models.Character, order models.Order) bool {
+// it's based upon the quantity on hand in bank, not counting items in flight. An input
+// doesn't count stock earmarked for other orders than the one it is for.
+func ShouldFulfilOrder(ctx context.Context, r *actions.Runner, c models.Character, order models.Order, staging *Staging) bool {
 	// refresh char data
 	c, err := r.GetMyCharacterInfo(ctx, c.Name)
 	if err != nil {
@@ -42,7 +51,7 @@ func ShouldFulfilOrder(ctx context.Context, r *actions.Runner, c models.Characte
 		}
 	}
 
-	if (bankItem.Quantity + inventoryItem.Quantity) < order.Item.Quantity {
+	if availableFor(order, bankItem.Quantity+inventoryItem.Quantity, staging) < order.Item.Quantity {
 		logging.Get(ctx).Debug("order quantity is greater than quantity on hand", "resource", order.Item.Code, "required", order.Item.Quantity, "inventory", inventoryItem.Quantity, "bank", bankItem.Quantity)
 		return true
 	} else {
@@ -51,16 +60,52 @@ func ShouldFulfilOrder(ctx context.Context, r *actions.Runner, c models.Characte
 
 }
 
-// FulfilOrder will instruct the character to seek out and gather the required resource
-func FulfilOrder(ctx context.Context, r *actions.Runner, character string, order models.Order) ([]models.Order, error) {
-	l := logging.Get(ctx)
+// availableFor returns the quantity of the order item counted towards it, from the quantity on
+// hand. Stock earmarked for other orders isn't counted towards an input, so the same stock isn't
+// delivered, and earmarked, twice.
+func availableFor(order models.Order, onHand int, staging *Staging) int {
+	if order.For == 0 {
+		return onHand
+	}
+	return onHand - staging.EarmarkedExcept(order.Item.Code, order.For)
+}
 
-	// get character location
-	c, err := r.GetMyCharacterInfo(ctx, character)
+// OrderSource determines where an order is fulfilled from, an explicit order Action is used,
+// otherwise craftable items are crafted, then resource drops gathered, monster drops fought
+// for, and anything else bought from the grand exchange
+func OrderSource(ctx context.Context, r *actions.Runner, order models.Order, item models.Item) (string, error) {
+	if order.Action != "" {
+		return order.Action, nil
+	}
+	if item.Craft != nil {
+		return models.OrderCraft, nil
+	}
+
+	resources, err := r.GetResourcesByDrop(ctx, order.Item.Code)
 	if err != nil {
-		return nil, fmt.Errorf("get character info: %w", err)
+		return "", fmt.Errorf("get resources by drop: %w", err)
+	}
+	if len(resources) > 0 {
+		return models.OrderGather, nil
 	}
 
+	monsters, err := r.GetMonstersByDrop(ctx, order.Item.Code)
+	if err != nil {
+		return "", fmt.Errorf("get monsters by drop: %w", err)
+	}
+	if len(monsters) > 0 {
+		return models.OrderFight, nil
+	}
+
+	return models.OrderBuy, nil
+}
+
+// FulfilOrder will instruct the character to make progress on the order from its source,
+// crafting returns orders for any missing inputs. Fights heal by the health policy first. Crafts
+// leave items earmarked for other staged orders alone.
+func FulfilOrder(ctx context.Context, r *actions.Runner, character string, order models.Order, health models.HealthPolicy, staging *Staging) ([]models.Order, error) {
+	l := logging.Get(ctx)
+
 	// determine if it's a resource or a craft
 	item, err := r.GetItem(ctx, order.Item.Code)
 	l.Debug("get item details", "item", item)
@@ -68,114 +113,240 @@ func FulfilOrder(ctx context.Context, r *actions.Runner, character string, order
 		return nil, fmt.Errorf("get item: %w", err)
 	}
 
-	// resource only
-	if item.Craft == nil {
-		l.Debug("this is a gather resource")
-		// determine all resources that drop the order
-		resources, err := r.GetResourcesByDrop(ctx, order.Item.Code)
-		if err != nil {
-			return nil, fmt.Errorf("get resources by drop: %w", err)
-		}
+	source, err := OrderSource(ctx, r, order, item)
+	if err != nil {
+		return nil, err
+	}
+	l.Debug("fulfilling order", "order", order, "source", source)
 
-		if c.ShouldBank() {
-			dErr := DepositAll(ctx, r, character)
-			if dErr != nil {
-				return nil, fmt.Errorf("failed to deposit all: %w", dErr)
-			}
-		}
+	switch source {
+	case models.OrderGather:
+		return nil, gatherOrder(ctx, r, character, order)
+	case models.OrderFight:
+		return nil, fightOrder(ctx, r, character, order, health)
+	case models.OrderCraft:
+		return craftOrder(ctx, r, character, order, item, staging)
+	case models.OrderBuy:
+		return nil, buyOrder(ctx, r, character, order)
+	default:
+		return nil, fmt.Errorf("unknown order action: %s", source)
+	}
+}
+
+// gatherOrder gathers an inventory of the order item from the resource that drops it
+func gatherOrder(ctx context.Context, r *actions.Runner, character string, order models.Order) error {
+	c, err := r.GetMyCharacterInfo(ctx, character)
+	if err != nil {
+		return fmt.Errorf("get character info: %w", err)
+	}
 
-		// goto the nearest resource
-		gErr := Gather(ctx, r, character, resources[0])
-		if gErr != nil {
-			return nil, fmt.Errorf("failed to gather resources: %w", gErr)
+	// determine all resources that drop the order
+	resources, err := r.GetResourcesByDrop(ctx, order.Item.Code)
+	if err != nil {
+		return fmt.Errorf("get resources by drop: %w", err)
+	}
+	if len(resources) == 0 {
+		return fmt.Errorf("no resource drops: %s", order.Item.Code)
+	}
+	resources = slices.DeleteFunc(resources, func(res models.Resource) bool {
+		return c.GetSkillLevel(string(res.Skill)) < res.Level
+	})
+	if len(resources) == 0 {
+		return fmt.Errorf("no resource drops %s at the character's level: %w", order.Item.Code, RequirementsNotMet)
+	}
+
+	if c.ShouldBank() {
+		err = MakeRoom(ctx, r, character, bankingSpace(c))
+		if err != nil {
+			return fmt.Errorf("failed to make room: %w", err)
 		}
-	} else {
-		l.Debug("this is a craft resource")
-		cs, err := item.Craft.AsCraftSchema()
+		c, err = r.GetMyCharacterInfo(ctx, character)
 		if err != nil {
-			return nil, fmt.Errorf("get item craft schema: %w", err)
+			return fmt.Errorf("get character info: %w", err)
 		}
-		item.Skill = string(*cs.Skill)
-
-		// items
-		var newOrders []models.Order
-		var materials []models.Order
-		for _, input := range *cs.Items {
-			io := models.Order{
-				Item: models.SimpleItem{
-					Code:     input.Code,
-					Quantity: input.Quantity * order.Item.Quantity,
-				},
-				Concurrency: order.Concurrency,
-				Action:      "gather",
-			}
+	}
 
-			if ShouldFulfilOrder(ctx, r, c, io) {
-				l.Debug("missing required item for crafting", "order", io)
-				newOrders = append(newOrders, io)
-			} else {
-				materials = append(materials, io)
-			}
+	// goto the nearest resource
+	resource := slices.MinFunc(resources, func(a, b models.Resource) int {
+		return cmp.Compare(models.CalculateDistance(c.GetPosition(), a.GetCoords()), models.CalculateDistance(c.GetPosition(), b.GetCoords()))
+	})
+	err = Gather(ctx, r, character, resource)
+	if err != nil {
+		return fmt.Errorf("failed to gather resources: %w", err)
+	}
+	return nil
+}
+
+// fightOrder fights the lowest level monster that drops the order item, until the order
+// quantity is carried or the inventory fills, then deposits. Monsters above the character's
+// level are not fought.
+func fightOrder(ctx context.Context, r *actions.Runner, character string, order models.Order, health models.HealthPolicy) error {
+	c, err := r.GetMyCharacterInfo(ctx, character)
+	if err != nil {
+		return fmt.Errorf("get character info: %w", err)
+	}
+
+	monsters, err := r.GetMonstersByDrop(ctx, order.Item.Code)
+	if err != nil {
+		return fmt.Errorf("get monsters by drop: %w", err)
+	}
+	if len(monsters) == 0 {
+		return fmt.Errorf("no monster drops: %s", order.Item.Code)
+	}
+	monsters = slices.DeleteFunc(monsters, func(m models.Monster) bool {
+		return m.Level > c.Level
+	})
+	if len(monsters) == 0 {
+		return fmt.Errorf("no monster drops %s at the character's level: %w", order.Item.Code, RequirementsNotMet)
+	}
+	monster := slices.MinFunc(monsters, func(a, b models.Monster) int {
+		return cmp.Compare(a.Level, b.Level)
+	})
+
+	// fight at the monster, then deposit the drops at the bank nearest it
+	stops := []models.Location{
+		{Code: monster.Code, Type: string(client.Monster)},
+		{Code: string(client.Bank), Type: string(client.Bank)},
+	}
+	return TravelTrip(ctx, r, character, stops, true, func(i int, stop models.Location) error {
+		if i == 1 {
+			return DepositAll(ctx, r, character)
 		}
 
-		if len(newOrders) > 0 {
-			return newOrders, errors.New("requirements not met")
+		fErr := FightUntil(ctx, r, character, stop, false, health, func(c models.Character, _ int) bool {
+			return c.ShouldBank() || c.CountInventoryItem(order.Item.Code) >= order.Item.Quantity
+		})
+		if fErr != nil {
+			return fmt.Errorf("failed to fight for drops: %w", fErr)
 		}
+		return nil
+	})
+}
 
-		l.Debug("all items present for crafting!")
+// craftOrder crafts the missing quantity of the order item in batches, returning orders for any
+// missing inputs. Items earmarked for other orders aren't counted, the order's own earmarks are.
+func craftOrder(ctx context.Context, r *actions.Runner, character string, order models.Order, item models.Item, staging *Staging) ([]models.Order, error) {
+	l := logging.Get(ctx)
 
-		// check inventory
+	c, err := r.GetMyCharacterInfo(ctx, character)
+	if err != nil {
+		return nil, fmt.Errorf("get character info: %w", err)
+	}
 
-		// go to bank
+	if item.Craft == nil {
+		return nil, fmt.Errorf("item is not craftable: %s", order.Item.Code)
+	}
+	cs, err := item.Craft.AsCraftSchema()
+	if err != nil {
+		return nil, fmt.Errorf("get item craft schema: %w", err)
+	}
 
-		tErr := Travel(ctx, r, character, models.Location{
-			Code: "bank",
-			Type: "bank",
-		})
-		if tErr != nil {
-			return nil, tErr
-		}
+	bank, err := r.GetBankItems(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get bank items: %w", err)
+	}
 
-		// deposit all
-		dErr := DepositAll(ctx, r, character)
-		if dErr != nil {
-			return nil, fmt.Errorf("failed to deposit all: %w", dErr)
-		}
+	// only craft what's missing, stock already made for the order counts towards it
+	stock := bank.Count(order.Item.Code) + c.CountInventoryItem(order.Item.Code) - staging.EarmarkedExcept(order.Item.Code, order.ID)
+	missing := order.Item.Quantity - stock
+	if missing <= 0 {
+		return nil, nil
+	}
+	yield := 1
+	if cs.Quantity != nil {
+		yield = *cs.Quantity
+	}
+	crafts := (missing + yield - 1) / yield
 
-		// withdraw items
-		for _, mat := range materials {
-			l.Info("withdrawing item", "code", mat.Item.Code, "qty", mat.Item.Quantity)
-			resp, wErr := r.Withdraw(ctx, character, mat.Item.Code, mat.Item.Quantity)
-			if wErr != nil {
-				return nil, wErr
-			}
-			cooldown := time.Until(resp.CooldownSchema.Expiration)
-			c.CharacterSchema = resp.CharacterResponse.CharacterSchema
-			time.Sleep(cooldown)
+	// items
+	var newOrders []models.Order
+	for _, input := range *cs.Items {
+		io := models.Order{
+			Item: models.SimpleItem{
+				Code:     input.Code,
+				Quantity: input.Quantity * crafts,
+			},
+			Concurrency: order.Concurrency,
+			Priority:    order.Priority,
+			Deadline:    order.Deadline,
+			For:         order.ID,
 		}
 
-		// craft items
-		l.Info("traveling to workshop", "skill", item.Skill)
-		err = Travel(ctx, r, character, models.Location{
-			Code: item.Skill,
-			Type: "workshop",
-		})
-		if err != nil {
-			return nil, err
+		have := bank.Count(input.Code) + c.CountInventoryItem(input.Code) - staging.EarmarkedExcept(input.Code, order.ID)
+		if have < io.Item.Quantity {
+			l.Debug("missing required item for crafting", "order", io)
+			newOrders = append(newOrders, io)
 		}
+	}
 
-		skillresp, err := r.Craft(ctx, character, order.Item.Code, order.Item.Quantity)
-		if err != nil {
-			return nil, fmt.Errorf("failed to craft %s, %d, code: %w", order.Item.Code, order.Item.Quantity, err)
-		}
-		l.Info("skill response", "response", skillresp.SkillInfo)
+	if len(newOrders) > 0 {
+		return newOrders, RequirementsNotMet
+	}
 
-		cooldown := time.Until(skillresp.Response.CooldownSchema.Expiration)
-		c.CharacterSchema = skillresp.Response.CharacterResponse.CharacterSchema
-		time.Sleep(cooldown)
+	l.Debug("all items present for crafting!")
+	return nil, CraftBatched(ctx, r, character, order.Item.Code, crafts, staging, order.ID)
+}
 
-		return nil, nil
+// buyOrder buys the missing quantity of the order item from the grand exchange, and deposits it
+func buyOrder(ctx context.Context, r *actions.Runner, character string, order models.Order) error {
+	l := logging.Get(ctx)
+
+	price, err := r.GetBuyPrice(ctx, order.Item.Code)
+	if err != nil {
+		return fmt.Errorf("get buy price: %w", err)
+	}
+	if price == 0 {
+		return fmt.Errorf("item is not for sale: %s", order.Item.Code)
+	}
+
+	bank, err := r.GetBankItems(ctx)
+	if err != nil {
+		return fmt.Errorf("get bank items: %w", err)
+	}
+	missing := order.Item.Quantity - bank.Count(order.Item.Code)
+	if missing <= 0 {
+		return nil
+	}
+
+	// deposit at the bank on the way to the grand exchange, then deposit what was bought
+	stops := []models.Location{
+		{Code: string(client.Bank), Type: string(client.Bank)},
+		{Code: string(client.GrandExchange), Type: string(client.GrandExchange)},
+		{Code: string(client.Bank), Type: string(client.Bank)},
 	}
+	var qty int
+	return TravelTrip(ctx, r, character, stops, true, func(i int, _ models.Location) error {
+		switch i {
+		case 0:
+			dErr := DepositAll(ctx, r, character)
+			if dErr != nil {
+				return fmt.Errorf("failed to deposit all: %w", dErr)
+			}
 
-	return nil, nil
+			c, cErr := r.GetMyCharacterInfo(ctx, character)
+			if cErr != nil {
+				return fmt.Errorf("get character info: %w", cErr)
+			}
+			// the items kept in the inventory limit what can be carried
+			qty = min(missing, c.FreeSpace().Items)
+			if qty <= 0 {
+				return fmt.Errorf("no inventory space to buy %s", order.Item.Code)
+			}
+			if c.Gold < qty*price {
+				return fmt.Errorf("not enough gold to buy %d %s at %d", qty, order.Item.Code, price)
+			}
+			return nil
+		case 1:
+			resp, bErr := r.Buy(ctx, character, order.Item.Code, qty, price)
+			if bErr != nil {
+				return fmt.Errorf("failed to buy %s, %d: %w", order.Item.Code, qty, bErr)
+			}
+			cooldown := resp.GetCooldownDuration()
+			l.Info("bought item", "code", order.Item.Code, "qty", qty, "price", price, "cooldown", cooldown)
+			time.Sleep(cooldown)
+			return nil
+		default:
+			return DepositAll(ctx, r, character)
+		}
+	})
 }
@@ -0,0 +1,378 @@
+package engine
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/promiseofcake/artifactsmmo-go-client/client"
+
+	"github.com/promiseofcake/artifactsmmo-engine/internal/actions"
+	"github.com/promiseofcake/artifactsmmo-engine/internal/models"
+)
+
+// approximate cooldowns used to estimate plans when there's no history, the game adjusts
+// these based upon skill levels and gear, so treat them as rough upper bounds
+const (
+	moveCooldownPerTile = 5 * time.Second
+	bankCooldown        = 3 * time.Second
+	gatherCooldown      = 25 * time.Second
+	craftCooldown       = 5 * time.Second
+	fightCooldown       = 30 * time.Second
+)
+
+// planner simulates order fulfilment against a virtual copy of the bank
+// and a single character, without performing any actions
+type planner struct {
+	r         *actions.Runner
+	character models.Character
+	position  models.Coords
+	stock     map[string]int
+	banks     models.Locations
+	workshops models.Locations
+	exchanges models.Locations
+	plan      models.Plan
+}
+
+// PlanOrders simulates the decomposition FulfilOrder performs for each of the orders,
+// in the order the engine picks them, for the given character
+func PlanOrders(ctx context.Context, r *actions.Runner, c models.Character, orders []models.Order) (models.Plan, error) {
+	bank, err := r.GetBankItems(ctx)
+	if err != nil {
+		return models.Plan{}, fmt.Errorf("get bank items: %w", err)
+	}
+
+	banks, err := r.GetMapsByContentType(ctx, client.Bank)
+	if err != nil {
+		return models.Plan{}, fmt.Errorf("get bank maps: %w", err)
+	}
+
+	workshops, err := r.GetMapsByContentType(ctx, client.Workshop)
+	if err != nil {
+		return models.Plan{}, fmt.Errorf("get workshop maps: %w", err)
+	}
+
+	exchanges, err := r.GetMapsByContentType(ctx, client.GrandExchange)
+	if err != nil {
+		return models.Plan{}, fmt.Errorf("get grand exchange maps: %w", err)
+	}
+
+	p := &planner{
+		r:         r,
+		character: c,
+		position:  c.GetPosition(),
+		stock:     make(map[string]int),
+		banks:     banks,
+		workshops: workshops,
+		exchanges: exchanges,
+	}
+	for _, b := range bank {
+		p.stock[b.Code] += b.Quantity
+	}
+	for _, slot := range *c.Inventory {
+		if slot.Code != "" {
+			p.stock[slot.Code] += slot.Quantity
+		}
+	}
+
+	// orders are fulfilled by priority, then deadline
+	orders = slices.Clone(orders)
+	slices.SortStableFunc(orders, func(a, b models.Order) int {
+		switch {
+		case a.Before(b):
+			return -1
+		case b.Before(a):
+			return 1
+		}
+		return 0
+	})
+	for _, o := range orders {
+		err = p.fulfil(ctx, o, 0)
+		if err != nil {
+			return models.Plan{}, err
+		}
+	}
+
+	return p.plan, nil
+}
+
+// fulfil simulates a single order, recursing into the inputs of crafts
+func (p *planner) fulfil(ctx context.Context, order models.Order, depth int) error {
+	code := order.Item.Code
+	qty := order.Item.Quantity
+
+	onHand := p.stock[code]
+	if onHand >= qty {
+		p.plan.Steps = append(p.plan.Steps, models.PlanStep{
+			Depth:    depth,
+			Action:   "skip",
+			Code:     code,
+			Quantity: qty,
+			Target:   "on hand",
+		})
+		return nil
+	}
+
+	item, err := p.r.GetItem(ctx, code)
+	if err != nil {
+		return fmt.Errorf("get item: %w", err)
+	}
+
+	source, err := OrderSource(ctx, p.r, order, item)
+	if err != nil {
+		return err
+	}
+	switch source {
+	case models.OrderGather:
+		return p.gather(ctx, code, qty-onHand, depth)
+	case models.OrderFight:
+		return p.fight(ctx, code, qty-onHand, depth)
+	case models.OrderBuy:
+		return p.buy(code, qty-onHand, depth)
+	}
+	if item.Craft == nil {
+		p.unsupported(code, qty-onHand, depth, "item is not craftable")
+		return nil
+	}
+
+	cs, err := item.Craft.AsCraftSchema()
+	if err != nil {
+		return fmt.Errorf("get item craft schema: %w", err)
+	}
+
+	for _, input := range *cs.Items {
+		err = p.fulfil(ctx, models.Order{
+			Item: models.SimpleItem{
+				Code:     input.Code,
+				Quantity: input.Quantity * qty,
+			},
+			Concurrency: order.Concurrency,
+		}, depth+1)
+		if err != nil {
+			return err
+		}
+	}
+
+	yield := 1
+	if cs.Quantity != nil {
+		yield = *cs.Quantity
+	}
+	var materials models.SimpleItems
+	for _, input := range *cs.Items {
+		materials = append(materials, models.SimpleItem{Code: input.Code, Quantity: input.Quantity})
+	}
+	batch := p.character.MaxBatch(materials, yield)
+	if batch == 0 {
+		return fmt.Errorf("inventory too small to craft: %s", code)
+	}
+
+	step := models.PlanStep{
+		Depth:    depth,
+		Action:   "craft",
+		Code:     code,
+		Quantity: qty,
+		Target:   string(*cs.Skill),
+	}
+	for remaining := qty; remaining > 0; remaining -= batch {
+		n := min(batch, remaining)
+
+		// deposit all, then withdraw every input
+		_, err = p.travel(&step, p.banks, string(client.Bank))
+		if err != nil {
+			return err
+		}
+		step.Actions += 1 + len(*cs.Items)
+		step.Duration += time.Duration(1+len(*cs.Items)) * bankCooldown
+
+		// craft the batch at the workshop
+		_, err = p.travel(&step, p.workshops, string(*cs.Skill))
+		if err != nil {
+			return err
+		}
+		step.Actions++
+		step.Duration += p.craftDuration(string(*cs.Skill), n)
+	}
+	for _, input := range *cs.Items {
+		p.stock[input.Code] -= input.Quantity * qty
+	}
+	p.stock[code] += qty * yield
+
+	// return to deposit the output
+	_, err = p.travel(&step, p.banks, string(client.Bank))
+	if err != nil {
+		return err
+	}
+	step.Actions++
+	step.Duration += bankCooldown
+
+	p.plan.Steps = append(p.plan.Steps, step)
+	return nil
+}
+
+// gather simulates gathering the missing quantity of a raw material, banking whenever
+// the character inventory fills
+func (p *planner) gather(ctx context.Context, code string, missing int, depth int) error {
+	resources, err := p.r.GetResourcesByDrop(ctx, code)
+	if err != nil {
+		return fmt.Errorf("get resources by drop: %w", err)
+	}
+
+	if len(resources) == 0 {
+		p.unsupported(code, missing, depth, "no resource drops this item")
+		return nil
+	}
+
+	resource := resources[0]
+	step := models.PlanStep{
+		Depth:    depth,
+		Action:   "gather",
+		Code:     code,
+		Quantity: missing,
+		Target:   resource.Code,
+	}
+
+	step.Duration += moveDuration(p.position, resource.GetCoords())
+	p.position = resource.GetCoords()
+
+	// use the character's recorded gathers of the resource when known
+	gathers := missing
+	cooldown := gatherCooldown
+	if stats, known := gatherHistory.Get(p.character.Name, resource.Code); known && stats.Gathers > 0 {
+		if stats.Items > 0 {
+			gathers = (missing*stats.Gathers + stats.Items - 1) / stats.Items
+		}
+		cooldown = stats.Cooldown / time.Duration(stats.Gathers)
+	}
+	step.Actions += gathers
+	step.Duration += time.Duration(gathers) * cooldown
+
+	// a character banks once their inventory is 90% full
+	capacity := max(1, p.character.InventoryMaxItems*9/10)
+	trips := (missing + capacity - 1) / capacity
+	bank, found := p.banks.Nearest(string(client.Bank), p.position)
+	if !found {
+		return fmt.Errorf("no bank found")
+	}
+	step.Actions += trips
+	step.Duration += time.Duration(trips) * (2*moveDuration(resource.GetCoords(), bank.Coords) + bankCooldown)
+	p.position = bank.Coords
+
+	p.stock[code] += missing
+	p.plan.Steps = append(p.plan.Steps, step)
+	return nil
+}
+
+// fight simulates fighting the lowest level monster which drops the item, assuming a drop
+// every fight, so this is a lower bound
+func (p *planner) fight(ctx context.Context, code string, missing int, depth int) error {
+	monsters, err := p.r.GetMonstersByDrop(ctx, code)
+	if err != nil {
+		return fmt.Errorf("get monsters by drop: %w", err)
+	}
+	if len(monsters) == 0 {
+		p.unsupported(code, missing, depth, "no monster drops this item")
+		return nil
+	}
+	monster := slices.MinFunc(monsters, func(a, b models.Monster) int {
+		return cmp.Compare(a.Level, b.Level)
+	})
+
+	step := models.PlanStep{
+		Depth:    depth,
+		Action:   "fight",
+		Code:     code,
+		Quantity: missing,
+		Target:   monster.Code,
+	}
+	monsterLocations, err := p.r.GetMapsByContentCode(ctx, monster.Code)
+	if err != nil {
+		return fmt.Errorf("get monster maps: %w", err)
+	}
+	_, err = p.travel(&step, monsterLocations, monster.Code)
+	if err != nil {
+		return err
+	}
+	step.Actions += missing
+	step.Duration += time.Duration(missing) * fightCooldown
+
+	// deposit the drops
+	_, err = p.travel(&step, p.banks, string(client.Bank))
+	if err != nil {
+		return err
+	}
+	step.Actions++
+	step.Duration += bankCooldown
+
+	p.stock[code] += missing
+	p.plan.Steps = append(p.plan.Steps, step)
+	return nil
+}
+
+// buy simulates buying the item from the grand exchange and depositing it
+func (p *planner) buy(code string, missing int, depth int) error {
+	step := models.PlanStep{
+		Depth:    depth,
+		Action:   "buy",
+		Code:     code,
+		Quantity: missing,
+		Target:   string(client.GrandExchange),
+	}
+	_, err := p.travel(&step, p.exchanges, string(client.GrandExchange))
+	if err != nil {
+		return err
+	}
+	step.Actions++
+	step.Duration += bankCooldown
+
+	_, err = p.travel(&step, p.banks, string(client.Bank))
+	if err != nil {
+		return err
+	}
+	step.Actions++
+	step.Duration += bankCooldown
+
+	p.stock[code] += missing
+	p.plan.Steps = append(p.plan.Steps, step)
+	return nil
+}
+
+// unsupported adds a step for an item the planner can't source
+func (p *planner) unsupported(code string, missing int, depth int, reason string) {
+	p.plan.Steps = append(p.plan.Steps, models.PlanStep{
+		Depth:    depth,
+		Action:   "unsupported",
+		Code:     code,
+		Quantity: missing,
+		Target:   reason,
+	})
+}
+
+// travel adds the move to the nearest location with the given code to the step
+func (p *planner) travel(step *models.PlanStep, locations models.Locations, code string) (models.Location, error) {
+	loc, found := locations.Nearest(code, p.position)
+	if !found {
+		return models.Location{}, fmt.Errorf("no location found for: %s", code)
+	}
+	if loc.Coords != p.position {
+		step.Actions++
+		step.Duration += moveDuration(p.position, loc.Coords)
+	}
+	p.position = loc.Coords
+	return loc, nil
+}
+
+// craftDuration estimates the cooldown of crafting a batch, from the character's recorded crafts
+// of the skill when known
+func (p *planner) craftDuration(skill string, n int) time.Duration {
+	if cooldown, known := skillHistory.Cooldown(p.character.Name, skill); known {
+		return cooldown
+	}
+	return time.Duration(n) * craftCooldown
+}
+
+// moveDuration estimates the move cooldown between two coords
+func moveDuration(from, to models.Coords) time.Duration {
+	return time.Duration(models.CalculateDistance(from, to)) * moveCooldownPerTile
+}
@@ -0,0 +1,304 @@
+package engine
+
+import (
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/promiseofcake/artifactsmmo-engine/internal/models"
+)
+
+// OrderQueue is a priority queue of orders shared by all characters. The orders for an item
+// can be cancelled, along with the orders queued for their inputs, after which any attempt to
+// re-queue them is dropped until restored. Fulfilled maintain orders are parked until their
+// stock drops. Every queued order is given an ID, kept when it is queued again.
+type OrderQueue struct {
+	mu           sync.Mutex
+	orders       []models.Order
+	parked       map[string]models.Order
+	cancelled    map[string]bool
+	cancelledIDs map[int]bool
+	lastID       int
+	dropped      func(models.Order)
+}
+
+// NewOrderQueue returns an empty OrderQueue
+func NewOrderQueue() *OrderQueue {
+	return &OrderQueue{
+		parked:       make(map[string]models.Order),
+		cancelled:    make(map[string]bool),
+		cancelledIDs: make(map[int]bool),
+	}
+}
+
+// OnDrop sets a func called with every order dropped from the queue, once expired or cancelled
+func (q *OrderQueue) OnDrop(f func(models.Order)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.dropped = f
+}
+
+// Push adds an order to the back of the queue and returns its ID, unless it has been cancelled,
+// in which case the orders queued for its inputs are cancelled too and 0 is returned
+func (q *OrderQueue) Push(o models.Order) int {
+	var cancelled []models.Order
+	defer func() {
+		q.drop(cancelled)
+	}()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.isCancelled(o) {
+		cancelled = q.cancel([]int{o.ID})
+		return 0
+	}
+	o = q.identify(o)
+	q.orders = append(q.orders, o)
+	return o.ID
+}
+
+// Queued determines if the order with the given ID is queued
+func (q *OrderQueue) Queued(id int) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return slices.ContainsFunc(q.orders, func(o models.Order) bool { return o.ID == id })
+}
+
+// Cancelled determines if the order has been cancelled
+func (q *OrderQueue) Cancelled(o models.Order) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.isCancelled(o)
+}
+
+// isCancelled determines if the order, or the order it is an input for, has been cancelled. Orders
+// for an item are cancelled by code, inputs are cancelled by ID. The caller holds the lock.
+func (q *OrderQueue) isCancelled(o models.Order) bool {
+	return (o.For == 0 && q.cancelled[o.Item.Code]) || q.cancelledIDs[o.ID] || q.cancelledIDs[o.For]
+}
+
+// identify gives the order an ID, if it doesn't have one. The caller holds the lock.
+func (q *OrderQueue) identify(o models.Order) models.Order {
+	if o.ID == 0 {
+		q.lastID++
+		o.ID = q.lastID
+	}
+	return o
+}
+
+// drop calls the drop func for each of the orders, without the lock held as it may queue orders
+func (q *OrderQueue) drop(orders []models.Order) {
+	q.mu.Lock()
+	dropped := q.dropped
+	q.mu.Unlock()
+	if dropped == nil {
+		return
+	}
+	for _, o := range orders {
+		dropped(o)
+	}
+}
+
+// Next removes and returns the highest value order the eligible func accepts, by priority
+// then deadline, and otherwise first in first out. Orders past their deadline are dropped.
+func (q *OrderQueue) Next(now time.Time, eligible func(models.Order) bool) (models.Order, bool) {
+	return q.NextFunc(now, eligible, models.Order.Before)
+}
+
+// NextFunc removes and returns the first order the eligible func accepts, as ordered by
+// the before func, and otherwise first in first out. Orders past their deadline are dropped.
+func (q *OrderQueue) NextFunc(now time.Time, eligible func(models.Order) bool, before func(a, b models.Order) bool) (models.Order, bool) {
+	var expired []models.Order
+	defer func() {
+		q.drop(expired)
+	}()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var remaining []models.Order
+	for _, o := range q.orders {
+		if o.Expired(now) {
+			slog.Info("dropping expired order", "order", o)
+			expired = append(expired, o)
+			continue
+		}
+		remaining = append(remaining, o)
+	}
+	q.orders = remaining
+
+	best := -1
+	for i, o := range q.orders {
+		if !eligible(o) {
+			continue
+		}
+		if best < 0 || before(o, q.orders[best]) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return models.Order{}, false
+	}
+
+	o := q.orders[best]
+	q.orders = slices.Delete(q.orders, best, best+1)
+	return o, true
+}
+
+// Park holds a fulfilled maintain order until it is re-activated, concurrent copies
+// of the order are parked once
+func (q *OrderQueue) Park(o models.Order) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.isCancelled(o) {
+		return
+	}
+	if _, ok := q.parked[o.Item.Code]; ok {
+		return
+	}
+
+	// drop the remaining concurrent copies, they are queued again on re-activation
+	var remaining []models.Order
+	for _, p := range q.orders {
+		if p.Item.Code != o.Item.Code {
+			remaining = append(remaining, p)
+		}
+	}
+	q.orders = remaining
+	q.parked[o.Item.Code] = o
+}
+
+// Parked returns the parked orders
+func (q *OrderQueue) Parked() []models.Order {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var parked []models.Order
+	for _, o := range q.parked {
+		parked = append(parked, o)
+	}
+	return parked
+}
+
+// Unpark queues a parked order again, once per its concurrency
+func (q *OrderQueue) Unpark(code string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	o, ok := q.parked[code]
+	if !ok {
+		return
+	}
+	delete(q.parked, code)
+	o.ID = 0
+	for n := 0; n < max(1, o.Concurrency); n++ {
+		q.orders = append(q.orders, q.identify(o))
+	}
+}
+
+// Cancel removes the queued orders for the item code, and the orders queued for their inputs,
+// dropping any future pushes of them until the code is restored. Orders for the item queued as
+// inputs of other orders are kept. The IDs of the removed orders are returned.
+func (q *OrderQueue) Cancel(code string) []int {
+	var cancelled []models.Order
+	defer func() {
+		q.drop(cancelled)
+	}()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.cancelled[code] = true
+	delete(q.parked, code)
+
+	var ids []int
+	for _, o := range q.orders {
+		if o.For == 0 && o.Item.Code == code {
+			ids = append(ids, o.ID)
+		}
+	}
+	cancelled = q.cancel(ids)
+	return orderIDs(cancelled)
+}
+
+// CancelIDs removes the queued orders with the given IDs, and the orders queued for their inputs,
+// dropping any future pushes of them. The IDs of the removed orders are returned.
+func (q *OrderQueue) CancelIDs(ids []int) []int {
+	var cancelled []models.Order
+	defer func() {
+		q.drop(cancelled)
+	}()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	cancelled = q.cancel(ids)
+	return orderIDs(cancelled)
+}
+
+// cancel marks the orders with the given IDs cancelled, and removes them from the queue along with
+// the orders queued for their inputs, in turn. The caller holds the lock.
+func (q *OrderQueue) cancel(ids []int) []models.Order {
+	for _, id := range ids {
+		if id != 0 {
+			q.cancelledIDs[id] = true
+		}
+	}
+
+	var cancelled []models.Order
+	for {
+		var remaining []models.Order
+		n := len(cancelled)
+		for _, o := range q.orders {
+			if q.cancelledIDs[o.ID] || q.cancelledIDs[o.For] {
+				q.cancelledIDs[o.ID] = true
+				cancelled = append(cancelled, o)
+			} else {
+				remaining = append(remaining, o)
+			}
+		}
+		q.orders = remaining
+		if len(cancelled) == n {
+			return cancelled
+		}
+	}
+}
+
+// orderIDs returns the IDs of the orders
+func orderIDs(orders []models.Order) []int {
+	var ids []int
+	for _, o := range orders {
+		ids = append(ids, o.ID)
+	}
+	return ids
+}
+
+// Restore allows orders for a cancelled item code to be queued again
+func (q *OrderQueue) Restore(code string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.cancelled, code)
+}
+
+// Len returns the number of queued orders
+func (q *OrderQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.orders)
+}
+
+// Demand returns the quantity wanted by the queued orders for each item code
+func (q *OrderQueue) Demand() map[string]int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	demand := make(map[string]int)
+	for _, o := range q.orders {
+		// concurrent copies of an order share the quantity
+		demand[o.Item.Code] = max(demand[o.Item.Code], o.Item.Quantity)
+	}
+	return demand
+}
+
+// Orders returns a copy of the queued orders
+func (q *OrderQueue) Orders() []models.Order {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return slices.Clone(q.orders)
+}
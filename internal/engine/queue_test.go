@@ -0,0 +1,104 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/promiseofcake/artifactsmmo-go-client/client"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/promiseofcake/artifactsmmo-engine/internal/models"
+)
+
+func order(code string) models.Order {
+	return models.Order{Item: models.SimpleItem{Code: code, Quantity: 1}, Concurrency: 1}
+}
+
+func anyOrder(models.Order) bool {
+	return true
+}
+
+func TestOrderQueue(t *testing.T) {
+	q := NewOrderQueue()
+	q.Push(order("copper_dagger"))
+	q.Push(order("wooden_staff"))
+	q.Push(order("copper_dagger"))
+	assert.Equal(t, 3, q.Len())
+
+	o, ok := q.Next(time.Now(), anyOrder)
+	assert.True(t, ok)
+	assert.Equal(t, "copper_dagger", o.Item.Code)
+
+	// cancelling drops queued orders and future pushes
+	q.Cancel("copper_dagger")
+	q.Push(order("copper_dagger"))
+	assert.Equal(t, 1, q.Len())
+
+	o, ok = q.Next(time.Now(), anyOrder)
+	assert.True(t, ok)
+	assert.Equal(t, "wooden_staff", o.Item.Code)
+
+	_, ok = q.Next(time.Now(), anyOrder)
+	assert.False(t, ok)
+
+	// restoring allows the item to be queued again
+	q.Restore("copper_dagger")
+	q.Push(order("copper_dagger"))
+	assert.Equal(t, 1, q.Len())
+}
+
+func TestOrderQueueDemand(t *testing.T) {
+	q := NewOrderQueue()
+	q.Push(order("copper_dagger"))
+	q.Push(order("copper_dagger"))
+	q.Push(models.Order{Item: models.SimpleItem{Code: "copper", Quantity: 30}, Concurrency: 1})
+
+	assert.Equal(t, map[string]int{"copper_dagger": 1, "copper": 30}, q.Demand())
+}
+
+func TestOrderQueueNext(t *testing.T) {
+	now := time.Now()
+	q := NewOrderQueue()
+	q.Push(order("copper_dagger"))
+	q.Push(models.Order{Item: models.SimpleItem{Code: "expired"}, Deadline: now.Add(-time.Minute)})
+	q.Push(models.Order{Item: models.SimpleItem{Code: "soon"}, Deadline: now.Add(time.Minute)})
+	q.Push(models.Order{Item: models.SimpleItem{Code: "urgent"}, Priority: 5, Characters: []string{"Bilnor"}})
+
+	milnor := func(o models.Order) bool {
+		return o.EligibleFor(models.Character{CharacterSchema: client.CharacterSchema{Name: "Milnor"}})
+	}
+
+	// the urgent order isn't eligible, and the expired order is dropped
+	o, ok := q.Next(now, milnor)
+	assert.True(t, ok)
+	assert.Equal(t, "soon", o.Item.Code)
+	assert.Equal(t, 2, q.Len())
+
+	o, ok = q.Next(now, anyOrder)
+	assert.True(t, ok)
+	assert.Equal(t, "urgent", o.Item.Code)
+
+	o, ok = q.Next(now, milnor)
+	assert.True(t, ok)
+	assert.Equal(t, "copper_dagger", o.Item.Code)
+}
+
+func TestOrderQueuePark(t *testing.T) {
+	q := NewOrderQueue()
+	o := models.Order{Item: models.SimpleItem{Code: "copper", Quantity: 10}, Concurrency: 2, Maintain: true}
+	q.Push(o)
+	q.Push(o)
+
+	q.Park(o)
+	assert.Equal(t, 0, q.Len())
+	assert.Equal(t, []models.Order{o}, q.Parked())
+
+	q.Unpark("copper")
+	assert.Equal(t, 2, q.Len())
+	assert.Empty(t, q.Parked())
+
+	// cancelling drops parked orders
+	q.Park(o)
+	q.Cancel("copper")
+	assert.Empty(t, q.Parked())
+}
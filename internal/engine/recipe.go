@@ -0,0 +1,59 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/promiseofcake/artifactsmmo-engine/internal/actions"
+	"github.com/promiseofcake/artifactsmmo-engine/internal/models"
+)
+
+// BuildRecipe resolves the full crafting tree for the given quantity of an item,
+// annotating each node with its source and the quantity already in the bank
+func BuildRecipe(ctx context.Context, r *actions.Runner, code string, qty int, bank models.SimpleItems) (*models.Recipe, error) {
+	item, err := r.GetItem(ctx, code)
+	if err != nil {
+		return nil, fmt.Errorf("get item: %w", err)
+	}
+
+	recipe := &models.Recipe{
+		Code:     code,
+		Name:     item.Name,
+		Quantity: qty,
+		Banked:   bank.Count(code),
+	}
+
+	if item.Craft == nil {
+		recipe.Resources, err = r.GetResourcesByDrop(ctx, code)
+		if err != nil {
+			return nil, fmt.Errorf("get resources by drop: %w", err)
+		}
+		recipe.Monsters, err = r.GetMonstersByDrop(ctx, code)
+		if err != nil {
+			return nil, fmt.Errorf("get monsters by drop: %w", err)
+		}
+		return recipe, nil
+	}
+
+	cs, err := item.Craft.AsCraftSchema()
+	if err != nil {
+		return nil, fmt.Errorf("get item craft schema: %w", err)
+	}
+	recipe.Skill = string(*cs.Skill)
+	if cs.Level != nil {
+		recipe.Level = *cs.Level
+	}
+	if cs.Quantity != nil {
+		recipe.Yield = *cs.Quantity
+	}
+
+	for _, input := range *cs.Items {
+		child, cErr := BuildRecipe(ctx, r, input.Code, input.Quantity*recipe.Crafts(), bank)
+		if cErr != nil {
+			return nil, cErr
+		}
+		recipe.Inputs = append(recipe.Inputs, child)
+	}
+
+	return recipe, nil
+}
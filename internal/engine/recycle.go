@@ -0,0 +1,118 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/promiseofcake/artifactsmmo-go-client/client"
+
+	"github.com/promiseofcake/artifactsmmo-engine/internal/actions"
+	"github.com/promiseofcake/artifactsmmo-engine/internal/logging"
+	"github.com/promiseofcake/artifactsmmo-engine/internal/models"
+)
+
+var NoItemsToRecycle = errors.New("no items to recycle")
+
+// Recycle withdraws a batch of the largest surplus of recyclable bank items, recycles it at the
+// workshop of its craft skill, and deposits the recovered materials. Items earmarked for staged
+// orders are left alone.
+func Recycle(ctx context.Context, r *actions.Runner, character string, policy models.RecyclePolicy, staging *Staging) error {
+	l := logging.Get(ctx)
+
+	// empty the inventory to maximize the batch, this also travels to the bank
+	err := DepositAll(ctx, r, character)
+	if err != nil {
+		return fmt.Errorf("failed to deposit all: %w", err)
+	}
+
+	item, qty, err := withdrawSurplus(ctx, r, character, policy, staging)
+	if err != nil {
+		return err
+	}
+
+	// recycle at the workshop, then deposit the materials at the bank nearest it
+	l.Info("traveling to workshop", "skill", item.Skill)
+	stops := []models.Location{
+		{Code: item.Skill, Type: string(client.Workshop)},
+		{Code: string(client.Bank), Type: string(client.Bank)},
+	}
+	return TravelTrip(ctx, r, character, stops, true, func(i int, _ models.Location) error {
+		if i == 1 {
+			return DepositAll(ctx, r, character)
+		}
+
+		resp, rErr := r.Recycle(ctx, character, item.Code, qty)
+		if rErr != nil {
+			return fmt.Errorf("failed to recycle %s, %d: %w", item.Code, qty, rErr)
+		}
+		cooldown := resp.GetCooldownDuration()
+		l.Info("recycled item", "code", item.Code, "qty", qty, "recovered", resp.Items, "cooldown", cooldown)
+		time.Sleep(cooldown)
+		return Checkpoint(ctx, resp.CharacterResponse)
+	})
+}
+
+// withdrawSurplus withdraws the largest recyclable surplus the character has room to recycle,
+// returning the item with its craft skill and the quantity withdrawn. The refine lock is held
+// while withdrawing, so refiners and recyclers don't contend for items.
+func withdrawSurplus(ctx context.Context, r *actions.Runner, character string, policy models.RecyclePolicy, staging *Staging) (models.Item, int, error) {
+	l := logging.Get(ctx)
+
+	l.Debug("waiting for refine lock")
+	r.RefineMutex.Lock()
+	defer r.RefineMutex.Unlock()
+
+	banked, err := r.GetBankItems(ctx)
+	if err != nil {
+		return models.Item{}, 0, fmt.Errorf("get bank items: %w", err)
+	}
+
+	c, err := r.GetMyCharacterInfo(ctx, character)
+	if err != nil {
+		return models.Item{}, 0, fmt.Errorf("get character info: %w", err)
+	}
+
+	for _, s := range policy.Surplus(banked) {
+		qty := s.Quantity - staging.Earmarked(s.Code)
+		if qty <= 0 {
+			continue
+		}
+
+		item, iErr := r.GetItem(ctx, s.Code)
+		if iErr != nil {
+			return models.Item{}, 0, fmt.Errorf("get item: %w", iErr)
+		}
+		if item.Craft == nil {
+			l.Warn("item is not recyclable", "code", s.Code)
+			continue
+		}
+		cs, csErr := item.Craft.AsCraftSchema()
+		if csErr != nil {
+			return models.Item{}, 0, fmt.Errorf("get item craft schema: %w", csErr)
+		}
+
+		// recycling is crafting in reverse, the recovered materials need the room a craft would
+		var materials models.SimpleItems
+		for _, mat := range *cs.Items {
+			materials = append(materials, models.SimpleItem{Code: mat.Code, Quantity: mat.Quantity})
+		}
+		qty = min(qty, c.MaxBatch(materials, 1))
+		if qty <= 0 {
+			continue
+		}
+
+		l.Info("withdrawing item", "code", s.Code, "qty", qty)
+		resp, wErr := r.Withdraw(ctx, character, s.Code, qty)
+		if wErr != nil {
+			return models.Item{}, 0, fmt.Errorf("failed to withdraw %s, %d: %w", s.Code, qty, wErr)
+		}
+		time.Sleep(resp.GetCooldownDuration())
+
+		item.Skill = string(*cs.Skill)
+		return item, qty, nil
+	}
+
+	return models.Item{}, 0, NoItemsToRecycle
+}
@@ -1,11 +1,9 @@
 package engine
 
 import (
-	"cmp"
 	"context"
 	"errors"
 	"fmt"
-	"math"
 	"slices"
 	"time"
 
@@ -18,61 +16,25 @@ import (
 
 var NoItemsToRefine = errors.New("no items to refine")
 
-func Refine(ctx context.Context, r *actions.Runner, character string) error {
-	var err error
-	// assign character
+// Refine crafts the best item, by the given metric, that the character can make from the bank
+// stock using the given refining skills. Demand is the quantity of each item wanted by orders,
+// items earmarked for staged orders are left alone.
+func Refine(ctx context.Context, r *actions.Runner, character string, skills []string, metric string, demand map[string]int, staging *Staging) error {
 	l := logging.Get(ctx)
 
-	defer func() {
-		// Note that while correct uses of TryLock do exist, they are rare,
-		// and use of TryLock is often a sign of a deeper problem
-		// in a particular use of mutexes.
-		r.RefineMutex.TryLock()
-		r.RefineMutex.Unlock()
-	}()
-
-	// start by traveling to the bank to reduce the mutex lock time
-	err = Travel(ctx, r, character, models.Location{
-		Code: "bank",
-		Type: "bank",
-	})
-	if err != nil {
-		return err
-	}
-
-	// empty the inventory to maximize refining
-	err = DepositAll(ctx, r, character)
+	// empty the inventory to maximize refining, this also travels to the bank
+	err := DepositAll(ctx, r, character)
 	if err != nil {
 		return err
 	}
 
 	// get all bank items, determine what's available to refine
-	// lock here, so we don't have contention for items
-	l.Debug("waiting for refine lock")
-	r.RefineMutex.Lock()
 	banked, err := r.GetBankItems(ctx)
 	if err != nil {
 		l.Error("failed to get bank items", "character", character, "error", err)
 		return err
 	}
 
-	// given all the banked items, get information on each of them and call
-	// them resources
-	var resources models.Items
-	for _, b := range banked {
-		item, iErr := r.GetItem(ctx, b.Code)
-		if iErr != nil {
-			l.Error("failed to get item info", "character", character, "error", iErr)
-			return iErr
-		}
-
-		item.Quantity = b.Quantity
-		// fishing / cooking is the only thing that is 1:1, we need more math
-		if item.Type == "resource" && item.Craft == nil {
-			resources = append(resources, &item)
-		}
-	}
-
 	// get character state
 	c, err := r.GetMyCharacterInfo(ctx, character)
 	if err != nil {
@@ -80,137 +42,136 @@ func Refine(ctx context.Context, r *actions.Runner, character string) error {
 		return err
 	}
 
-	// given the resources we have (hydrated bank items), try to determine which of these items
-	// our character is available to refine based upon their level
-	// and put these into a new list
+	// treat the bank as a production graph, the refining skills can make any of their recipes
+	// at the character's level from a banked item. Intermediates (e.g. bars) are inputs to
+	// further refinement, so chains are refined step by step.
 	var refinable models.Items
-	for _, res := range resources {
-		var refineLevel int
-		var skillType string
-
-		// given the resource's subtype, lookup this characters
-		// skill level, and fetch all items one could make
-		// with the current given skill level
-		switch res.Subtype {
-		case string(client.ResourceSchemaSkillWoodcutting):
-			refineLevel = c.WoodcuttingLevel
-			skillType = string(client.CraftSchemaSkillWoodcutting)
-		case string(client.ResourceSchemaSkillMining):
-			refineLevel = c.MiningLevel
-			skillType = string(client.CraftSchemaSkillMining)
-		case string(client.ResourceSchemaSkillFishing):
-			refineLevel = c.CookingLevel
-			skillType = string(client.CraftSchemaSkillCooking)
+	for _, skill := range skills {
+		recipes, rErr := r.GetRecipes(ctx, skill)
+		if rErr != nil {
+			return rErr
 		}
+		for _, item := range recipes {
+			if item.Level > c.GetSkillLevel(skill) {
+				continue
+			}
+			if slices.ContainsFunc(item.CraftMaterials, func(mat *models.CraftResource) bool {
+				return banked.Count(mat.RequiredCode) > 0
+			}) {
+				refinable = append(refinable, item)
+			}
+		}
+	}
 
-		// min level current level - 10
-		// TODO make overridable
-		//minLevel := int(math.Max(0, float64(refineLevel-10)))
-
-		// allow all items to be refined
-		minLevel := 0
+	// determine how many of each item can be refined from the bank stock
+	var options []models.RefineOption
+	for _, item := range refinable {
+		setCount := refineSets(c, item, banked, staging)
+		if setCount <= 0 {
+			continue
+		}
 
-		// get items that match
-		items, iErr := r.GetItems(ctx, minLevel, refineLevel, skillType, res.Code)
-		if iErr != nil {
-			return iErr
+		option := models.RefineOption{
+			Item:   item,
+			Sets:   setCount,
+			Demand: max(0, demand[item.Code]-banked.Count(item.Code)),
 		}
-		if len(items) == 0 {
-			continue
+		if metric == models.RefineByPrice {
+			option.Price, err = r.GetSellPrice(ctx, item.Code)
+			if err != nil {
+				return err
+			}
 		}
-		refinable = append(refinable, items...)
+		options = append(options, option)
 	}
 
-	if len(refinable) == 0 {
+	best, ok := models.ChooseRefine(options, metric)
+	if !ok {
 		return NoItemsToRefine
 	}
+	l.Debug("chose item to refine", "item", best.Item.Code, "metric", metric, "sets", best.Sets, "demand", best.Demand, "price", best.Price)
 
-	// sort list with higher level items first
-	slices.SortFunc(refinable, func(a, b *models.Item) int {
-		return cmp.Compare(b.Level, a.Level)
-	})
-
-	// look over all the resources we have in the bank
-	// look over all the refinable items that match that
-	// resource, and then go for the first one.
-
-	// bad loop
-	var totalResourceCount int
-	var available models.Items
-	for _, resourceToRefine := range refinable {
-		totalResourceCount = 0
-		for _, mat := range resourceToRefine.CraftMaterials {
-			totalResourceCount += mat.CostPerResource
-			for _, res := range resources {
-				if mat.RequiredCode == res.Code {
-					mat.Available = res.Quantity
-				}
-			}
-		}
+	resourceToRefine := best.Item
+	resourceToRefine.Quantity, err = withdrawRefine(ctx, r, c, resourceToRefine, best.Sets, staging)
+	if err != nil {
+		return err
+	}
 
-		maxSetsByInventory := int(math.Floor(float64(c.InventoryMaxItems) / float64(totalResourceCount)))
-		setCount := maxSetsByInventory
+	l.Info("preparing to refine", "resource", resourceToRefine.Name, "qty", resourceToRefine.Quantity)
 
-		for _, mat := range resourceToRefine.CraftMaterials {
-			maxSetsByResource := mat.Available / mat.CostPerResource
-			if maxSetsByResource < setCount {
-				setCount = maxSetsByResource
-			}
+	// refine at the workshop, then deposit at the bank nearest it
+	l.Info("traveling to workshop", "skill", resourceToRefine.Skill)
+	stops := []models.Location{
+		{Code: resourceToRefine.Skill, Type: string(client.Workshop)},
+		{Code: string(client.Bank), Type: string(client.Bank)},
+	}
+	return TravelTrip(ctx, r, character, stops, true, func(i int, _ models.Location) error {
+		if i == 1 {
+			return DepositAll(ctx, r, character)
 		}
 
-		if setCount > 0 {
-			resourceToRefine.Quantity = setCount
-			available = append(available, resourceToRefine)
+		skillresp, cErr := r.Craft(ctx, character, resourceToRefine.Code, resourceToRefine.Quantity)
+		if cErr != nil {
+			return fmt.Errorf("failed to craft %s, %d, code: %w", resourceToRefine.Code, resourceToRefine.Quantity, cErr)
 		}
-	}
+		l.Info("skill response", "response", skillresp.SkillInfo)
 
-	resourceToRefine := available[0]
-	for _, mat := range resourceToRefine.CraftMaterials {
-		qty := resourceToRefine.Quantity * mat.CostPerResource
-		l.Info("withdrawing item", "code", mat.RequiredCode, "qty", qty)
-		resp, wErr := r.Withdraw(ctx, character, mat.RequiredCode, qty)
-		if wErr != nil {
-			return wErr
-		}
-		cooldown := time.Until(resp.CooldownSchema.Expiration)
-		c.CharacterSchema = resp.CharacterResponse.CharacterSchema
+		cooldown := time.Until(skillresp.Response.CooldownSchema.Expiration)
+		skillHistory.Record(character, resourceToRefine.Skill, skillresp.SkillInfo.Xp, cooldown)
 		time.Sleep(cooldown)
-	}
-	r.RefineMutex.Unlock()
-
-	l.Info("preparing to refine", "resource", resourceToRefine.Name, "qty", resourceToRefine.Quantity)
-
-	// need to travel to refinement location
-	l.Info("traveling to workshop", "skill", resourceToRefine.Skill)
-	err = Travel(ctx, r, character, models.Location{
-		Code: resourceToRefine.Skill,
-		Type: "workshop",
+		return Checkpoint(ctx, skillresp.CharacterResponse)
 	})
-	if err != nil {
-		return err
+}
+
+// refineSets returns how many times the item can be refined from the bank stock which isn't
+// earmarked, limited by both the inventory item count and slots
+func refineSets(c models.Character, item *models.Item, banked models.SimpleItems, staging *Staging) int {
+	var materials models.SimpleItems
+	for _, mat := range item.CraftMaterials {
+		materials = append(materials, models.SimpleItem{Code: mat.RequiredCode, Quantity: mat.CostPerResource})
+		mat.Available = max(0, banked.Count(mat.RequiredCode)-staging.Earmarked(mat.RequiredCode))
 	}
 
-	// need to refine the item
-	skillresp, err := r.Craft(ctx, character, resourceToRefine.Code, resourceToRefine.Quantity)
-	if err != nil {
-		return fmt.Errorf("failed to craft %s, %d, code: %w", resourceToRefine.Code, resourceToRefine.Quantity, err)
+	setCount := c.MaxBatch(materials, 1)
+	for _, mat := range item.CraftMaterials {
+		setCount = min(setCount, mat.Available/mat.CostPerResource)
 	}
-	l.Info("skill response", "response", skillresp.SkillInfo)
+	return setCount
+}
 
-	cooldown := time.Until(skillresp.Response.CooldownSchema.Expiration)
-	c.CharacterSchema = skillresp.Response.CharacterResponse.CharacterSchema
-	time.Sleep(cooldown)
+// withdrawRefine withdraws the materials to refine the item up to the given number of times. The
+// refine lock is held only while withdrawing, so there's no contention for items, and the stock is
+// checked again as other characters may have taken it. It returns the number of sets withdrawn.
+func withdrawRefine(ctx context.Context, r *actions.Runner, c models.Character, item *models.Item, sets int, staging *Staging) (int, error) {
+	l := logging.Get(ctx)
+	l.Debug("waiting for refine lock")
+	r.RefineMutex.Lock()
+	defer r.RefineMutex.Unlock()
 
-	// need to return to bank and deposit
-	err = DepositAll(ctx, r, character)
+	banked, err := r.GetBankItems(ctx)
 	if err != nil {
-		return err
+		return 0, err
+	}
+	sets = min(sets, refineSets(c, item, banked, staging))
+	if sets <= 0 {
+		return 0, NoItemsToRefine
 	}
 
-	return nil
+	for _, mat := range item.CraftMaterials {
+		qty := sets * mat.CostPerResource
+		l.Info("withdrawing item", "code", mat.RequiredCode, "qty", qty)
+		resp, wErr := r.Withdraw(ctx, c.Name, mat.RequiredCode, qty)
+		if wErr != nil {
+			return 0, wErr
+		}
+		time.Sleep(time.Until(resp.CooldownSchema.Expiration))
+	}
+	return sets, nil
 }
 
-func RefineAll(ctx context.Context, r *actions.Runner, character string) error {
+// RefineAll refines items from the bank using the given refining skills and metric,
+// NoItemsToRefine is returned when there's nothing to refine
+func RefineAll(ctx context.Context, r *actions.Runner, character string, skills []string, metric string, demand map[string]int, staging *Staging) error {
 	l := logging.Get(ctx)
 	c, err := r.GetMyCharacterInfo(ctx, character)
 	if err != nil {
@@ -227,14 +188,11 @@ func RefineAll(ctx context.Context, r *actions.Runner, character string) error {
 			return nil
 		default:
 			l.Debug("refining")
-			rErr := Refine(ctx, r, c.Name)
-			if rErr == nil || errors.Is(rErr, NoItemsToRefine) {
-				// no issue if nothing to refine
-				return nil
-			} else {
-				l.Error("failed to refine", "character", character, "error", rErr)
-				return rErr
+			rErr := Refine(ctx, r, c.Name, skills, metric, demand, staging)
+			if rErr != nil && !errors.Is(rErr, NoItemsToRefine) && !errors.Is(rErr, Preempted) {
+				l.Debug("refine failed", "character", character, "error", rErr)
 			}
+			return rErr
 		}
 	}
 }
@@ -0,0 +1,107 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/promiseofcake/artifactsmmo-engine/internal/logging"
+	"github.com/promiseofcake/artifactsmmo-engine/internal/models"
+)
+
+// StepDone is returned from a checkpoint when the character's routine step has met a stop condition,
+// it is a preemption so operations unwind as they do for other work
+var StepDone = fmt.Errorf("routine step done: %w", Preempted)
+
+// routineProgress is a character's place in their routine
+type routineProgress struct {
+	step     int
+	started  time.Time
+	count    int
+	finished bool
+}
+
+// nextStep returns the routine step the character works on, skipping steps which are done. A step
+// which hasn't started waits for its window, it returns nil until the window opens, and a step whose
+// window closed once started is done. It returns false when there's no routine or it has finished.
+func (a *Assignment) nextStep(c models.Character, now time.Time) (*models.RoutineStep, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	steps := a.cfg.Routine.Steps
+	if len(steps) == 0 || a.progress.finished {
+		return nil, false
+	}
+	for range steps {
+		step := steps[a.progress.step]
+		started := a.progress.started
+		closed := !step.Active(now)
+		if started.IsZero() {
+			if closed && !step.Done(c, now, 0, now) {
+				return nil, true
+			}
+			started = now
+		}
+		if !closed && !step.Done(c, started, a.progress.count, now) {
+			a.progress.started = started
+			return &step, true
+		}
+		if !a.advanceStep() {
+			return nil, false
+		}
+	}
+	return nil, true
+}
+
+// stepDone counts an action of the current routine step, and advances the routine if the step is
+// done or its window has closed
+func (a *Assignment) stepDone(c models.Character, now time.Time) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	steps := a.cfg.Routine.Steps
+	if len(steps) == 0 || a.progress.finished || a.progress.started.IsZero() {
+		return false
+	}
+	a.progress.count++
+	step := steps[a.progress.step]
+	if step.Active(now) && !step.Done(c, a.progress.started, a.progress.count, now) {
+		return false
+	}
+	a.advanceStep()
+	return true
+}
+
+// advanceStep moves the routine to its next step, it reports false once a routine which doesn't
+// repeat has finished. The caller holds the lock.
+func (a *Assignment) advanceStep() bool {
+	a.progress.step++
+	a.progress.started = time.Time{}
+	a.progress.count = 0
+	if a.progress.step < len(a.cfg.Routine.Steps) {
+		return true
+	}
+	a.progress.step = 0
+	if a.cfg.Routine.Repeat {
+		return true
+	}
+	a.progress.finished = true
+	return false
+}
+
+// routineCheckpoint returns a checkpoint which runs the scheduler's checks, then ends the current
+// routine step once it has met a stop condition against the character's live state
+func routineCheckpoint(ctx context.Context, a *Assignment, check func(models.Character) error) func(models.Character) error {
+	l := logging.Get(ctx)
+	return func(c models.Character) error {
+		err := check(c)
+		if err != nil {
+			return err
+		}
+		if a.stepDone(c, time.Now()) {
+			l.Info("routine step done")
+			return StepDone
+		}
+		return nil
+	}
+}
@@ -0,0 +1,101 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/promiseofcake/artifactsmmo-go-client/client"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/promiseofcake/artifactsmmo-engine/internal/models"
+)
+
+func TestRoutine(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	c := models.Character{CharacterSchema: client.CharacterSchema{Name: "Milnor", Level: 10}}
+
+	a, err := NewAssignment(CharacterConfig{Routine: models.Routine{Steps: []models.RoutineStep{
+		{Action: "forage", Count: 2},
+		{Action: "fight", Level: 10},
+		{Action: "refine", Duration: time.Hour},
+	}}})
+	assert.NoError(t, err)
+
+	step, ok := a.nextStep(c, now)
+	assert.True(t, ok)
+	assert.Equal(t, "forage", step.Action)
+
+	// the count is checked against each action at a checkpoint
+	var preempted bool
+	stepCtx := withCheckpoint(ctx, routineCheckpoint(ctx, a, scheduler(ctx, a, NewCoordinator(nil, NewOrderQueue()), operationPriority, &preempted)))
+	assert.NoError(t, Checkpoint(stepCtx, c))
+	assert.ErrorIs(t, Checkpoint(stepCtx, c), StepDone)
+	assert.ErrorIs(t, StepDone, Preempted)
+	assert.False(t, preempted)
+
+	// the level target is already met, so the fight step is skipped
+	step, ok = a.nextStep(c, now)
+	assert.True(t, ok)
+	assert.Equal(t, "refine", step.Action)
+	assert.False(t, a.stepDone(c, now.Add(59*time.Minute)))
+	assert.True(t, a.stepDone(c, now.Add(time.Hour)))
+
+	// without repeat the routine finishes
+	step, ok = a.nextStep(c, now)
+	assert.False(t, ok)
+	assert.Nil(t, step)
+
+	// a changed routine starts over, and waits while no step is in its window
+	closed := now.Add(2*time.Hour).Format("15:04") + "-" + now.Add(3*time.Hour).Format("15:04")
+	assert.NoError(t, a.Set(CharacterConfig{Routine: models.Routine{Repeat: true, Steps: []models.RoutineStep{
+		{Action: "forage", Window: closed},
+	}}}))
+	step, ok = a.nextStep(c, now)
+	assert.True(t, ok)
+	assert.Nil(t, step)
+}
+
+func TestRoutineWindow(t *testing.T) {
+	now := time.Now()
+	c := models.Character{CharacterSchema: client.CharacterSchema{Name: "Milnor", Level: 10}}
+	closed := now.Add(2*time.Hour).Format("15:04") + "-" + now.Add(3*time.Hour).Format("15:04")
+	open := now.Add(-time.Hour).Format("15:04") + "-" + now.Add(time.Hour).Format("15:04")
+
+	// a step waits for its window rather than being skipped, even when the routine doesn't repeat
+	a, err := NewAssignment(CharacterConfig{Routine: models.Routine{Steps: []models.RoutineStep{
+		{Action: "forage", Window: closed},
+		{Action: "fight"},
+	}}})
+	assert.NoError(t, err)
+	step, ok := a.nextStep(c, now)
+	assert.True(t, ok)
+	assert.Nil(t, step)
+	step, ok = a.nextStep(c, now)
+	assert.True(t, ok)
+	assert.Nil(t, step)
+
+	// a step whose stop condition is met is skipped while its window is closed
+	a, err = NewAssignment(CharacterConfig{Routine: models.Routine{Steps: []models.RoutineStep{
+		{Action: "forage", Window: closed, Level: 10},
+		{Action: "fight"},
+	}}})
+	assert.NoError(t, err)
+	step, ok = a.nextStep(c, now)
+	assert.True(t, ok)
+	assert.Equal(t, "fight", step.Action)
+
+	// a running step ends once its window closes
+	a, err = NewAssignment(CharacterConfig{Routine: models.Routine{Steps: []models.RoutineStep{
+		{Action: "forage", Window: open},
+		{Action: "fight"},
+	}}})
+	assert.NoError(t, err)
+	step, ok = a.nextStep(c, now)
+	assert.True(t, ok)
+	assert.Equal(t, "forage", step.Action)
+	step, ok = a.nextStep(c, now.Add(2*time.Hour))
+	assert.True(t, ok)
+	assert.Equal(t, "fight", step.Action)
+}
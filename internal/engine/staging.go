@@ -0,0 +1,135 @@
+package engine
+
+import (
+	"sync"
+
+	"github.com/promiseofcake/artifactsmmo-engine/internal/models"
+)
+
+// Staging tracks items in the bank earmarked for an order. An order waiting on inputs is staged
+// rather than re-queued, producers earmark the inputs as they deposit them, and the order is
+// woken once every input has been delivered. Staged orders are keyed by order ID.
+type Staging struct {
+	mu     sync.Mutex
+	staged map[int]*stagedOrder
+}
+
+// stagedOrder is an order waiting on its inputs
+type stagedOrder struct {
+	order     models.Order
+	waiting   bool
+	needs     map[string]int
+	delivered map[string]int
+}
+
+// NewStaging returns an empty Staging area
+func NewStaging() *Staging {
+	return &Staging{
+		staged: make(map[int]*stagedOrder),
+	}
+}
+
+// Stage holds an order until the given inputs have been earmarked for it, any earmarks
+// already made for the order are kept
+func (s *Staging) Stage(o models.Order, needs []models.Order) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	so, ok := s.staged[o.ID]
+	if !ok {
+		so = &stagedOrder{delivered: make(map[string]int)}
+		s.staged[o.ID] = so
+	}
+	so.order = o
+	so.waiting = true
+	so.needs = make(map[string]int)
+	for _, n := range needs {
+		so.needs[n.Item.Code] = n.Item.Quantity
+	}
+}
+
+// Earmark records the delivery of an input for the staged order, if every input has now
+// been delivered the order is returned to be woken
+func (s *Staging) Earmark(id int, code string, qty int) (models.Order, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	so, ok := s.staged[id]
+	if !ok || !so.waiting {
+		return models.Order{}, false
+	}
+	so.delivered[code] = max(so.delivered[code], qty)
+
+	for c, n := range so.needs {
+		if so.delivered[c] < n {
+			return models.Order{}, false
+		}
+	}
+	// keep the earmarks until the order is released, but don't wake it twice
+	so.waiting = false
+	return so.order, true
+}
+
+// Earmarked returns the quantity of the item earmarked for staged orders, a nil Staging has none
+func (s *Staging) Earmarked(code string) int {
+	return s.EarmarkedExcept(code, 0)
+}
+
+// EarmarkedExcept returns the quantity of the item earmarked for staged orders other than the
+// order with the given ID, which may use its own earmarks. A nil Staging has none.
+func (s *Staging) EarmarkedExcept(code string, id int) int {
+	if s == nil {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var qty int
+	for key, so := range s.staged {
+		if key != id {
+			qty += so.delivered[code]
+		}
+	}
+	return qty
+}
+
+// Release drops the staged order and its earmarks, once its inputs are consumed or it is cancelled
+func (s *Staging) Release(id int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.staged, id)
+}
+
+// Cancel drops the staged orders the match func accepts and their earmarks, returning their IDs
+func (s *Staging) Cancel(match func(models.Order) bool) []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var ids []int
+	for id, so := range s.staged {
+		if match(so.order) {
+			ids = append(ids, id)
+			delete(s.staged, id)
+		}
+	}
+	return ids
+}
+
+// Staged determines if the order with the given ID is staged
+func (s *Staging) Staged(id int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.staged[id]
+	return ok
+}
+
+// Unstage drops the staged order and its earmarks, returning the order if it was still waiting
+// so it can be queued again
+func (s *Staging) Unstage(id int) (models.Order, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	so, ok := s.staged[id]
+	if !ok || !so.waiting {
+		return models.Order{}, false
+	}
+	delete(s.staged, id)
+	return so.order, true
+}
@@ -0,0 +1,128 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/promiseofcake/artifactsmmo-engine/internal/models"
+)
+
+func TestStaging(t *testing.T) {
+	s := NewStaging()
+	dagger := order("copper_dagger")
+	dagger.ID = 1
+	other := order("copper_dagger")
+	other.ID = 2
+	inputs := []models.Order{
+		{Item: models.SimpleItem{Code: "copper", Quantity: 6}},
+		{Item: models.SimpleItem{Code: "ash_plank", Quantity: 2}},
+	}
+	// two orders for the same item wait on their own inputs
+	s.Stage(dagger, inputs)
+	s.Stage(other, inputs[:1])
+
+	_, ok := s.Earmark(1, "copper", 6)
+	assert.False(t, ok)
+	assert.Equal(t, 6, s.Earmarked("copper"))
+	assert.Equal(t, 0, s.EarmarkedExcept("copper", 1))
+
+	// deliveries for unknown orders are ignored
+	_, ok = s.Earmark(3, "ash_plank", 2)
+	assert.False(t, ok)
+
+	woken, ok := s.Earmark(1, "ash_plank", 2)
+	assert.True(t, ok)
+	assert.Equal(t, dagger, woken)
+
+	// the order is only woken once, the earmarks are held until released
+	_, ok = s.Earmark(1, "ash_plank", 2)
+	assert.False(t, ok)
+	assert.Equal(t, 2, s.Earmarked("ash_plank"))
+
+	// a woken order isn't unstaged
+	_, ok = s.Unstage(1)
+	assert.False(t, ok)
+
+	s.Release(1)
+	assert.Equal(t, 0, s.Earmarked("copper"))
+
+	// the other order is still waiting
+	unstaged, ok := s.Unstage(2)
+	assert.True(t, ok)
+	assert.Equal(t, other, unstaged)
+}
+
+func TestStagingDroppedInput(t *testing.T) {
+	q := NewOrderQueue()
+	co := NewCoordinator(nil, q)
+	now := time.Now()
+
+	dagger := order("copper_dagger")
+	dagger.ID = 100
+	input := models.Order{Item: models.SimpleItem{Code: "copper", Quantity: 6}, Deadline: now.Add(time.Minute), For: dagger.ID}
+	plank := models.Order{Item: models.SimpleItem{Code: "ash_plank", Quantity: 2}, For: dagger.ID}
+	co.Wait(dagger, []models.Order{input, plank})
+	q.Push(input)
+	q.Push(plank)
+
+	// the planks are delivered, then the copper input expires
+	o, ok := q.Next(now, func(o models.Order) bool { return o.Item.Code == "ash_plank" })
+	assert.True(t, ok)
+	co.Complete(context.Background(), o)
+	assert.Equal(t, 2, co.staging.Earmarked("ash_plank"))
+
+	_, ok = q.Next(now.Add(2*time.Minute), func(o models.Order) bool { return false })
+	assert.False(t, ok)
+
+	// the waiting order is queued again, and its earmarks released
+	assert.Equal(t, 0, co.staging.Earmarked("ash_plank"))
+	o, ok = q.Next(now, anyOrder)
+	assert.True(t, ok)
+	assert.Equal(t, dagger, o)
+
+	// cancelling an input does the same
+	co.Wait(dagger, []models.Order{plank})
+	q.Push(plank)
+	q.CancelIDs(orderIDs(q.Orders()))
+	o, ok = q.Next(now, anyOrder)
+	assert.True(t, ok)
+	assert.Equal(t, dagger, o)
+}
+
+func TestCoordinatorCancel(t *testing.T) {
+	q := NewOrderQueue()
+	co := NewCoordinator(nil, q)
+	now := time.Now()
+
+	// two orders stage for their inputs, both needing copper
+	q.Push(order("copper_dagger"))
+	q.Push(order("copper_ring"))
+	dagger, _ := q.Next(now, func(o models.Order) bool { return o.Item.Code == "copper_dagger" })
+	ring, _ := q.Next(now, anyOrder)
+	bar := models.Order{Item: models.SimpleItem{Code: "copper_bar", Quantity: 6}, For: dagger.ID}
+	co.Wait(dagger, []models.Order{bar})
+	q.Push(bar)
+	bar, _ = q.Next(now, anyOrder)
+	ore := models.Order{Item: models.SimpleItem{Code: "copper_ore", Quantity: 60}, For: bar.ID}
+	co.Wait(bar, []models.Order{ore})
+	q.Push(ore)
+	q.Push(models.Order{Item: models.SimpleItem{Code: "copper_bar", Quantity: 4}, For: ring.ID})
+	co.Wait(ring, []models.Order{{Item: models.SimpleItem{Code: "copper_bar", Quantity: 4}}})
+
+	// cancelling the dagger cancels its inputs in turn, but not the ring's copper
+	co.Cancel("copper_dagger")
+	orders := q.Orders()
+	assert.Len(t, orders, 1)
+	assert.Equal(t, ring.ID, orders[0].For)
+	_, ok := co.staging.Unstage(bar.ID)
+	assert.False(t, ok)
+	_, ok = co.staging.Unstage(dagger.ID)
+	assert.False(t, ok)
+
+	// an input pushed back after the cancel is dropped
+	q.Push(ore)
+	assert.Len(t, q.Orders(), 1)
+}
@@ -0,0 +1,204 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/promiseofcake/artifactsmmo-engine/internal/actions"
+	"github.com/promiseofcake/artifactsmmo-engine/internal/logging"
+	"github.com/promiseofcake/artifactsmmo-engine/internal/models"
+)
+
+// Supervisor runs a loop per character, and applies changes to the characters
+// and orders while they are running
+type Supervisor struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	r      *actions.Runner
+	queue  *OrderQueue
+	fleet  *Coordinator
+	errs   chan error
+	wg     sync.WaitGroup
+
+	mu         sync.Mutex
+	characters map[string]*Assignment
+	orders     map[string]models.Order
+	// running holds a channel per character closed when their loop exits
+	running map[string]chan struct{}
+}
+
+// NewSupervisor returns a Supervisor with no characters or orders
+func NewSupervisor(ctx context.Context, r *actions.Runner) *Supervisor {
+	ctx, cancel := context.WithCancel(ctx)
+	queue := NewOrderQueue()
+	return &Supervisor{
+		ctx:        ctx,
+		cancel:     cancel,
+		r:          r,
+		queue:      queue,
+		fleet:      NewCoordinator(r, queue),
+		errs:       make(chan error, 1),
+		characters: make(map[string]*Assignment),
+		orders:     make(map[string]models.Order),
+		running:    make(map[string]chan struct{}),
+	}
+}
+
+// SetCharacters starts loops for new characters, stops loops for removed characters and swaps
+// the config of existing characters. Changes take effect at each character's next checkpoint.
+// Nothing is applied unless every character's config is valid.
+func (s *Supervisor) SetCharacters(characters map[string]CharacterConfig) error {
+	for name, cfg := range characters {
+		err := cfg.Validate()
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for name, current := range s.characters {
+		if _, ok := characters[name]; !ok {
+			slog.Info("stopping character", "character", name)
+			current.Stop()
+			s.fleet.Remove(name)
+			delete(s.characters, name)
+		}
+	}
+
+	for name, cfg := range characters {
+		SetDepositPolicy(name, cfg.Deposit)
+		SetGoldPolicy(name, cfg.Gold)
+		if current, ok := s.characters[name]; ok {
+			err := current.Set(cfg)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			continue
+		}
+
+		a, err := NewAssignment(cfg)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		s.characters[name] = a
+		// a character removed and added again waits for their old loop to exit
+		s.running[name] = s.start(name, a, s.running[name])
+	}
+
+	return nil
+}
+
+// Preempt asks a running character to leave their current work at the next checkpoint
+func (s *Supervisor) Preempt(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.characters[name]
+	if !ok {
+		return fmt.Errorf("unknown character: %s", name)
+	}
+	a.Preempt()
+	return nil
+}
+
+// SetOrders queues new orders, and cancels queued orders which were removed along with the
+// orders made for their inputs. Orders are keyed by item code, a changed order is cancelled
+// and queued again.
+func (s *Supervisor) SetOrders(orders []models.Order) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	next := make(map[string]models.Order)
+	for _, o := range orders {
+		next[o.Item.Code] = o
+	}
+
+	for code, current := range s.orders {
+		if o, ok := next[code]; !ok || !o.Equal(current) {
+			slog.Info("cancelling order", "order", current)
+			s.fleet.Cancel(code)
+			delete(s.orders, code)
+		}
+	}
+
+	for code, o := range next {
+		if _, ok := s.orders[code]; ok {
+			continue
+		}
+		s.queue.Restore(code)
+		for n := 0; n < o.Concurrency; n++ {
+			slog.Debug("adding order to queue", "order", o)
+			s.queue.Push(o)
+		}
+		s.orders[code] = o
+	}
+}
+
+// Wait blocks until the Supervisor's context is done, or a character fails
+// in which case every character is stopped and the error is returned
+func (s *Supervisor) Wait() error {
+	var err error
+	select {
+	case <-s.ctx.Done():
+	case err = <-s.errs:
+		s.cancel()
+	}
+	s.wg.Wait()
+	return err
+}
+
+// start runs the character loop in the background once any previous loop for the character
+// has exited, it returns a channel closed when the loop exits
+func (s *Supervisor) start(name string, a *Assignment, previous <-chan struct{}) chan struct{} {
+	charCtx := logging.ContextWithLogger(s.ctx, slog.With("character", name))
+	logging.Get(charCtx).Info("starting execute engine", "actions", a.Actions())
+
+	done := make(chan struct{})
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer close(done)
+		if previous != nil {
+			select {
+			case <-previous:
+			case <-charCtx.Done():
+				return
+			}
+		}
+		err := WaitForCooldown(charCtx, s.r, name)
+		if err == nil {
+			err = Execute(charCtx, s.r, name, a, s.fleet)
+		}
+		if err != nil {
+			select {
+			case s.errs <- fmt.Errorf("%s: %w", name, err):
+			default:
+			}
+		}
+	}()
+	return done
+}
+
+// WaitForCooldown blocks until any cooldown the character is already under expires
+func WaitForCooldown(ctx context.Context, r *actions.Runner, character string) error {
+	l := logging.Get(ctx)
+	c, err := r.GetMyCharacterInfo(ctx, character)
+	if err != nil {
+		return fmt.Errorf("failed to get character: %w", err)
+	}
+
+	d, err := c.GetCooldownDuration()
+	if err != nil {
+		return fmt.Errorf("failed to get cooldown: %w", err)
+	}
+
+	if d > 0 {
+		l.Info("character on cooldown waiting...", "character", character, "duration", d)
+		time.Sleep(d)
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSupervisorSetCharacters(t *testing.T) {
+	s := NewSupervisor(context.Background(), nil)
+
+	// an invalid character stops the whole update from being applied
+	err := s.SetCharacters(map[string]CharacterConfig{
+		"Milnor": {Actions: []string{"forage"}},
+		"Bilnor": {Actions: []string{"dance"}},
+	})
+	assert.ErrorContains(t, err, "Bilnor: unknown action: dance")
+	assert.Empty(t, s.characters)
+	assert.Empty(t, s.running)
+}
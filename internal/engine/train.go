@@ -0,0 +1,209 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/promiseofcake/artifactsmmo-go-client/client"
+
+	"github.com/promiseofcake/artifactsmmo-engine/internal/actions"
+	"github.com/promiseofcake/artifactsmmo-engine/internal/logging"
+	"github.com/promiseofcake/artifactsmmo-engine/internal/models"
+)
+
+// InputsOrdered is returned when nothing can be crafted for training, and its inputs are ordered
+var InputsOrdered = errors.New("inputs ordered for crafting")
+
+// TrainCrafting trains the weapon, gear or jewelry crafting skill chosen by the training config.
+// The highest level recipe the bank stock allows is crafted in a batch, if nothing can be crafted
+// orders are queued for the missing inputs of the highest level recipe instead, once each, and
+// InputsOrdered is returned. The crafted items are then kept, recycled or sold.
+func TrainCrafting(ctx context.Context, r *actions.Runner, character string, training models.SkillTraining, craft models.CraftTraining, fleet *Coordinator) error {
+	l := logging.Get(ctx)
+
+	c, err := r.GetMyCharacterInfo(ctx, character)
+	if err != nil {
+		return fmt.Errorf("get character info: %w", err)
+	}
+
+	caps, err := skillLevelCaps(ctx, r, models.GearCraftingSkills)
+	if err != nil {
+		return fmt.Errorf("get skill level caps: %w", err)
+	}
+	skill, ok := c.ChooseSkill(models.GearCraftingSkills, training, caps, skillHistory.Last(character), func(s string) (float64, bool) {
+		return skillHistory.XPPerHour(character, s)
+	})
+	if !ok {
+		return NoSkillsToTrain
+	}
+
+	items, err := r.GetItems(ctx, skill.MinLevel, skill.CurrentLevel, skill.Code, "")
+	if err != nil {
+		return fmt.Errorf("get items: %w", err)
+	}
+	if len(items) == 0 {
+		return fmt.Errorf("no recipes found for %s", skill.Code)
+	}
+
+	banked, err := r.GetBankItems(ctx)
+	if err != nil {
+		return fmt.Errorf("get bank items: %w", err)
+	}
+
+	// stock earmarked for staged orders is left alone, so it's neither crafted with nor counted
+	// towards the inputs to order
+	var stock models.SimpleItems
+	for _, b := range banked {
+		stock = append(stock, models.SimpleItem{Code: b.Code, Quantity: max(0, b.Quantity-fleet.staging.Earmarked(b.Code))})
+	}
+
+	// determine how many of each recipe can be crafted from the bank stock in one batch
+	var craftable, all []models.RefineOption
+	for _, item := range items {
+		var materials models.SimpleItems
+		sets := c.InventoryMaxItems
+		for _, mat := range item.CraftMaterials {
+			materials = append(materials, models.SimpleItem{Code: mat.RequiredCode, Quantity: mat.CostPerResource})
+			sets = min(sets, stock.Count(mat.RequiredCode)/mat.CostPerResource)
+		}
+		batch := c.MaxBatch(materials, 1)
+
+		all = append(all, models.RefineOption{Item: item, Sets: batch})
+		if sets = min(sets, batch); sets > 0 {
+			craftable = append(craftable, models.RefineOption{Item: item, Sets: sets})
+		}
+	}
+
+	best, ok := models.ChooseRefine(craftable, models.RefineByXP)
+	if !ok {
+		// nothing can be crafted, so gather the inputs for a batch of the best recipe
+		best, _ = models.ChooseRefine(all, models.RefineByXP)
+		for _, m := range best.Item.Missing(stock, max(1, best.Sets)) {
+			if fleet.OrderTraining(models.Order{Item: m, Concurrency: 1}) {
+				l.Info("ordered missing input for crafting", "item", best.Item.Code, "input", m)
+			}
+		}
+		return InputsOrdered
+	}
+
+	l.Info("training crafting skill", "skill", skill.Code, "item", best.Item.Code, "qty", best.Sets)
+	before := banked.Count(best.Item.Code)
+	err = CraftBatched(ctx, r, character, best.Item.Code, best.Sets, fleet.staging, 0)
+	if err != nil {
+		return err
+	}
+
+	switch craft.Output {
+	case models.CraftOutputRecycle:
+		// recycle everything crafted, leaving what was banked before
+		policy := models.RecyclePolicy{Items: map[string]int{best.Item.Code: before}}
+		for {
+			err = Recycle(ctx, r, character, policy, fleet.staging)
+			if errors.Is(err, NoItemsToRecycle) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+		}
+	case models.CraftOutputSell:
+		banked, err = r.GetBankItems(ctx)
+		if err != nil {
+			return fmt.Errorf("get bank items: %w", err)
+		}
+		return SellItems(ctx, r, character, best.Item.Code, banked.Count(best.Item.Code)-before)
+	}
+	return nil
+}
+
+// SellItems withdraws the given quantity of an item from the bank in inventory sized batches, and
+// sells each batch on the grand exchange at the current sell price
+func SellItems(ctx context.Context, r *actions.Runner, character string, code string, qty int) error {
+	l := logging.Get(ctx)
+
+	price, err := r.GetSellPrice(ctx, code)
+	if err != nil {
+		return fmt.Errorf("get sell price: %w", err)
+	}
+	if price == 0 {
+		l.Warn("item can't be sold, keeping", "code", code)
+		return nil
+	}
+
+	// each batch is a trip to the bank, then to the grand exchange, choosing the bank on the way
+	stops := []models.Location{
+		{Code: string(client.Bank), Type: string(client.Bank)},
+		{Code: string(client.GrandExchange), Type: string(client.GrandExchange)},
+	}
+	for remaining := qty; remaining > 0; {
+		var n int
+		err = TravelTrip(ctx, r, character, stops, true, func(i int, _ models.Location) error {
+			if i == 0 {
+				var wErr error
+				n, wErr = withdrawUpTo(ctx, r, character, code, remaining)
+				return wErr
+			}
+
+			sold, sErr := r.Sell(ctx, character, code, n, price)
+			if sErr != nil {
+				return fmt.Errorf("failed to sell %s, %d: %w", code, n, sErr)
+			}
+			cooldown := sold.GetCooldownDuration()
+			l.Info("sold item", "code", code, "qty", n, "price", price, "cooldown", cooldown)
+			time.Sleep(cooldown)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		remaining -= n
+	}
+
+	return DepositAll(ctx, r, character)
+}
+
+// withdrawBatches withdraws the given quantity of an item from the bank in inventory sized batches,
+// handing each batch to the given func, then deposits what's left
+func withdrawBatches(ctx context.Context, r *actions.Runner, character string, code string, qty int, batch func(n int) error) error {
+	for remaining := qty; remaining > 0; {
+		n, err := withdrawUpTo(ctx, r, character, code, remaining)
+		if err != nil {
+			return err
+		}
+
+		err = batch(n)
+		if err != nil {
+			return err
+		}
+		remaining -= n
+	}
+
+	return DepositAll(ctx, r, character)
+}
+
+// withdrawUpTo deposits everything and withdraws as much of the quantity of an item as fits in the
+// inventory, returning the quantity withdrawn
+func withdrawUpTo(ctx context.Context, r *actions.Runner, character string, code string, qty int) (int, error) {
+	err := DepositAll(ctx, r, character)
+	if err != nil {
+		return 0, fmt.Errorf("failed to deposit all: %w", err)
+	}
+
+	c, err := r.GetMyCharacterInfo(ctx, character)
+	if err != nil {
+		return 0, fmt.Errorf("get character info: %w", err)
+	}
+	n := min(qty, c.MaxBatch(models.SimpleItems{{Code: code, Quantity: 1}}, 1))
+	if n <= 0 {
+		return 0, fmt.Errorf("inventory too small to withdraw: %s", code)
+	}
+
+	resp, err := r.Withdraw(ctx, character, code, n)
+	if err != nil {
+		return 0, fmt.Errorf("failed to withdraw %s, %d: %w", code, n, err)
+	}
+	time.Sleep(resp.GetCooldownDuration())
+	return n, nil
+}
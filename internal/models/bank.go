@@ -0,0 +1,112 @@
+package models
+
+import "slices"
+
+// Bank is the account bank's capacity and gold
+type Bank struct {
+	Slots             int
+	Expansions        int
+	NextExpansionCost int
+	Gold              int
+}
+
+// FreeSlots returns the slots left in the bank, given the banked items
+func (b Bank) FreeSlots(items SimpleItems) int {
+	return max(0, b.Slots-len(items))
+}
+
+// NewSlots returns the bank slots the deposits need, one per item not already banked
+func (b Bank) NewSlots(items SimpleItems, deposits SimpleItems) int {
+	var slots int
+	for _, d := range deposits {
+		if items.Count(d.Code) == 0 {
+			slots++
+		}
+	}
+	return slots
+}
+
+// Bank overflow actions, which clear an item from the bank to free its slot
+const (
+	OverflowSell    = "sell"
+	OverflowRecycle = "recycle"
+	OverflowDelete  = "delete"
+)
+
+// BankPolicy configures how the fleet keeps room in the shared bank
+type BankPolicy struct {
+	// WarnBelow warns when fewer bank slots are free
+	WarnBelow int `mapstructure:"warn_below"`
+	// Sell, Recycle and Delete list junk items cleared from the bank, in that order,
+	// when a deposit needs more slots than are free
+	Sell    []string `mapstructure:"sell"`
+	Recycle []string `mapstructure:"recycle"`
+	Delete  []string `mapstructure:"delete"`
+	// Pause lists low value items, resources which only drop them aren't gathered while the bank is full
+	Pause []string `mapstructure:"pause"`
+}
+
+// Clearance is a banked item to clear with an overflow action
+type Clearance struct {
+	Action string
+	Item   SimpleItem
+}
+
+// Overflow returns the banked items to clear to free the given number of slots, sold first,
+// then recycled, then deleted. Fewer are returned if the policy can't free enough.
+func (p BankPolicy) Overflow(items SimpleItems, slots int) []Clearance {
+	var clear []Clearance
+	for _, action := range []struct {
+		name  string
+		codes []string
+	}{
+		{OverflowSell, p.Sell},
+		{OverflowRecycle, p.Recycle},
+		{OverflowDelete, p.Delete},
+	} {
+		for _, code := range action.codes {
+			if len(clear) >= slots {
+				return clear
+			}
+			if qty := items.Count(code); qty > 0 && !slices.ContainsFunc(clear, func(c Clearance) bool { return c.Item.Code == code }) {
+				clear = append(clear, Clearance{Action: action.name, Item: SimpleItem{Code: code, Quantity: qty}})
+			}
+		}
+	}
+	return clear
+}
+
+// GoldPolicy configures how a character manages gold on bank visits
+type GoldPolicy struct {
+	// Sweep deposits the character's gold on every bank visit
+	Sweep bool `mapstructure:"sweep"`
+	// Keep is the gold the character carries when sweeping
+	Keep int `mapstructure:"keep"`
+	// ExpandBelow buys a bank expansion when fewer slots are free, 0 never expands
+	ExpandBelow int `mapstructure:"expand_below"`
+	// Reserve is the bank gold an expansion may not dip into
+	Reserve int `mapstructure:"reserve"`
+}
+
+// Applies determines if the policy has anything to do on a bank visit, a sweep when the character
+// carries more gold than they keep, or an expansion check which uses the bank gold
+func (p GoldPolicy) Applies(c Character) bool {
+	return p.Sweepable(c) > 0 || p.ExpandBelow > 0
+}
+
+// Sweepable returns the gold the character should deposit
+func (p GoldPolicy) Sweepable(c Character) int {
+	if !p.Sweep {
+		return 0
+	}
+	return max(0, c.Gold-p.Keep)
+}
+
+// ShouldExpand determines if a bank expansion should be bought, when the free slots fall below
+// the threshold and the bank gold covers the cost while keeping the reserve
+func (p GoldPolicy) ShouldExpand(b Bank, items SimpleItems) bool {
+	if p.ExpandBelow <= 0 || b.FreeSlots(items) >= p.ExpandBelow {
+		return false
+	}
+	return b.Gold-b.NextExpansionCost >= p.Reserve
+}
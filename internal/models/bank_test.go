@@ -0,0 +1,88 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/promiseofcake/artifactsmmo-go-client/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGoldPolicySweepable(t *testing.T) {
+	c := Character{CharacterSchema: client.CharacterSchema{Gold: 500}}
+
+	assert.Equal(t, 0, GoldPolicy{}.Sweepable(c))
+	assert.Equal(t, 0, GoldPolicy{Keep: 100}.Sweepable(c))
+	assert.Equal(t, 500, GoldPolicy{Sweep: true}.Sweepable(c))
+	assert.Equal(t, 400, GoldPolicy{Sweep: true, Keep: 100}.Sweepable(c))
+	assert.Equal(t, 0, GoldPolicy{Sweep: true, Keep: 1000}.Sweepable(c))
+}
+
+func TestGoldPolicyApplies(t *testing.T) {
+	c := Character{CharacterSchema: client.CharacterSchema{Gold: 500}}
+	assert.False(t, GoldPolicy{}.Applies(c))
+	assert.True(t, GoldPolicy{Sweep: true}.Applies(c))
+	assert.False(t, GoldPolicy{Sweep: true, Keep: 500}.Applies(c))
+	// expansions are paid for from the bank, so are checked whatever the character carries
+	assert.True(t, GoldPolicy{ExpandBelow: 5, Keep: 1000}.Applies(c))
+}
+
+func TestGoldPolicyShouldExpand(t *testing.T) {
+	items := SimpleItems{{Code: "copper", Quantity: 100}, {Code: "ash_wood", Quantity: 10}, {Code: "feather", Quantity: 3}}
+
+	tests := []struct {
+		name   string
+		policy GoldPolicy
+		bank   Bank
+		expect bool
+	}{
+		{"never expands by default", GoldPolicy{}, Bank{Slots: 3, Gold: 100000, NextExpansionCost: 4500}, false},
+		{"enough free slots", GoldPolicy{ExpandBelow: 2}, Bank{Slots: 5, Gold: 100000, NextExpansionCost: 4500}, false},
+		{"few free slots", GoldPolicy{ExpandBelow: 2}, Bank{Slots: 4, Gold: 100000, NextExpansionCost: 4500}, true},
+		{"not enough gold", GoldPolicy{ExpandBelow: 2}, Bank{Slots: 4, Gold: 4000, NextExpansionCost: 4500}, false},
+		{"keeps the reserve", GoldPolicy{ExpandBelow: 2, Reserve: 1000}, Bank{Slots: 4, Gold: 5000, NextExpansionCost: 4500}, false},
+		{"above the reserve", GoldPolicy{ExpandBelow: 2, Reserve: 500}, Bank{Slots: 4, Gold: 5000, NextExpansionCost: 4500}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expect, tt.policy.ShouldExpand(tt.bank, items))
+		})
+	}
+}
+
+func TestBankSlots(t *testing.T) {
+	items := SimpleItems{{Code: "copper", Quantity: 100}, {Code: "ash_wood", Quantity: 10}}
+	b := Bank{Slots: 3}
+
+	assert.Equal(t, 1, b.FreeSlots(items))
+	assert.Equal(t, 0, Bank{Slots: 1}.FreeSlots(items))
+	assert.Equal(t, 2, b.NewSlots(items, SimpleItems{{Code: "copper", Quantity: 5}, {Code: "feather", Quantity: 2}, {Code: "egg", Quantity: 1}}))
+	assert.Equal(t, 0, b.NewSlots(items, SimpleItems{{Code: "ash_wood", Quantity: 5}}))
+}
+
+func TestBankPolicyOverflow(t *testing.T) {
+	items := SimpleItems{
+		{Code: "copper_dagger", Quantity: 12},
+		{Code: "wooden_stick", Quantity: 3},
+		{Code: "feather", Quantity: 40},
+		{Code: "sap", Quantity: 7},
+	}
+	p := BankPolicy{
+		Sell:    []string{"feather", "golden_egg"},
+		Recycle: []string{"copper_dagger", "feather"},
+		Delete:  []string{"wooden_stick", "sap"},
+	}
+
+	assert.Empty(t, p.Overflow(items, 0))
+	assert.Empty(t, BankPolicy{}.Overflow(items, 2))
+	assert.Equal(t, []Clearance{
+		{Action: OverflowSell, Item: SimpleItem{Code: "feather", Quantity: 40}},
+		{Action: OverflowRecycle, Item: SimpleItem{Code: "copper_dagger", Quantity: 12}},
+	}, p.Overflow(items, 2))
+	assert.Equal(t, []Clearance{
+		{Action: OverflowSell, Item: SimpleItem{Code: "feather", Quantity: 40}},
+		{Action: OverflowRecycle, Item: SimpleItem{Code: "copper_dagger", Quantity: 12}},
+		{Action: OverflowDelete, Item: SimpleItem{Code: "wooden_stick", Quantity: 3}},
+		{Action: OverflowDelete, Item: SimpleItem{Code: "sap", Quantity: 7}},
+	}, p.Overflow(items, 10))
+}
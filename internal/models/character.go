@@ -1,14 +1,10 @@
 package models
 
 import (
-	"cmp"
 	"log/slog"
-	"slices"
 	"time"
 
 	"github.com/promiseofcake/artifactsmmo-go-client/client"
-
-	"github.com/promiseofcake/artifactsmmo-engine/internal/math"
 )
 
 // Character is our representation of the Player's character
@@ -27,40 +23,42 @@ func (c Character) CountInventory() int {
 	return count
 }
 
-type CharacterSkill struct {
-	Code         client.ResourceSchemaSkill
-	CurrentLevel int
-	MinLevel     int
-}
-
-func (c Character) ChooseWeakestSkill() CharacterSkill {
-	skills := []CharacterSkill{
-		{
-			Code:         client.ResourceSchemaSkillWoodcutting,
-			CurrentLevel: c.WoodcuttingLevel,
-			MinLevel:     map[bool]int{true: 20, false: math.Max(0, c.WoodcuttingLevel-10)}[c.WoodcuttingLevel == 35],
-		},
-		{
-			Code:         client.ResourceSchemaSkillMining,
-			CurrentLevel: c.MiningLevel,
-			MinLevel:     map[bool]int{true: 20, false: math.Max(0, c.MiningLevel-10)}[c.MiningLevel == 35],
-		},
-		{
-			Code:         client.ResourceSchemaSkillFishing,
-			CurrentLevel: c.FishingLevel,
-			MinLevel:     map[bool]int{true: 20, false: math.Max(0, c.FishingLevel-10)}[c.FishingLevel == 35],
-		},
+// CountInventoryItem returns the quantity of the given item code in the Character's Inventory
+func (c Character) CountInventoryItem(code string) int {
+	var count int
+	for _, item := range *c.Inventory {
+		if item.Code == code {
+			count += item.Quantity
+		}
 	}
+	return count
+}
 
-	// if maxed out, do mining
-	if c.FishingLevel == 35 && c.MiningLevel == 35 && c.WoodcuttingLevel == 35 {
-		return skills[1]
+// GetSkillLevel returns the Character's level for the given skill, or their
+// combat level if the skill is "combat"
+func (c Character) GetSkillLevel(skill string) int {
+	switch skill {
+	case "combat":
+		return c.Level
+	case string(client.CraftSchemaSkillMining):
+		return c.MiningLevel
+	case string(client.CraftSchemaSkillWoodcutting):
+		return c.WoodcuttingLevel
+	case string(client.ResourceSchemaSkillFishing):
+		return c.FishingLevel
+	case string(client.CraftSchemaSkillWeaponcrafting):
+		return c.WeaponcraftingLevel
+	case string(client.CraftSchemaSkillGearcrafting):
+		return c.GearcraftingLevel
+	case string(client.CraftSchemaSkillJewelrycrafting):
+		return c.JewelrycraftingLevel
+	case string(client.CraftSchemaSkillCooking):
+		return c.CookingLevel
+	case string(client.CraftSchemaSkillAlchemy):
+		return c.AlchemyLevel
+	default:
+		return 0
 	}
-
-	slices.SortFunc(skills, func(a, b CharacterSkill) int {
-		return cmp.Compare(a.CurrentLevel, b.CurrentLevel)
-	})
-	return skills[0]
 }
 
 // GetCooldownDuration returns the time.Duration remaining on the character for cooldown
@@ -90,3 +88,31 @@ func (c Character) ShouldBank() bool {
 		return false
 	}
 }
+
+// FreeSpace returns the free item capacity and empty slots in the Character's inventory
+func (c Character) FreeSpace() Space {
+	space := Space{Items: max(0, c.InventoryMaxItems-c.CountInventory())}
+	for _, slot := range *c.Inventory {
+		if slot.Code == "" {
+			space.Slots++
+		}
+	}
+	return space
+}
+
+// MaxBatch returns the most crafts of a recipe the Character can carry the materials for at once,
+// in their free inventory space. Both the item count and the distinct slots (one per material, plus
+// one for the output) are limited, yield is the number of items each craft produces.
+func (c Character) MaxBatch(materials SimpleItems, yield int) int {
+	free := c.FreeSpace()
+	if len(materials)+1 > free.Slots {
+		return 0
+	}
+
+	var perCraft int
+	for _, m := range materials {
+		perCraft += m.Quantity
+	}
+	perCraft = max(perCraft, yield, 1)
+	return free.Items / perCraft
+}
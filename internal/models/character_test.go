@@ -0,0 +1,90 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/promiseofcake/artifactsmmo-go-client/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountInventoryItem(t *testing.T) {
+	c := Character{CharacterSchema: client.CharacterSchema{
+		Inventory: &[]client.InventorySlot{
+			{Slot: 1, Code: "copper_ore", Quantity: 10},
+			{Slot: 2, Code: "ash_wood", Quantity: 3},
+			{Slot: 3, Code: "copper_ore", Quantity: 5},
+			{Slot: 4},
+		},
+	}}
+
+	tests := []struct {
+		code     string
+		expected int
+	}{
+		{"copper_ore", 15},
+		{"ash_wood", 3},
+		{"iron_ore", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			assert.Equal(t, tt.expected, c.CountInventoryItem(tt.code))
+		})
+	}
+}
+
+func TestGetSkillLevel(t *testing.T) {
+	c := Character{CharacterSchema: client.CharacterSchema{
+		Level:        12,
+		MiningLevel:  8,
+		CookingLevel: 3,
+		AlchemyLevel: 5,
+	}}
+
+	tests := []struct {
+		skill    string
+		expected int
+	}{
+		{"combat", 12},
+		{"mining", 8},
+		{"cooking", 3},
+		{"alchemy", 5},
+		{"unknown", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.skill, func(t *testing.T) {
+			assert.Equal(t, tt.expected, c.GetSkillLevel(tt.skill))
+		})
+	}
+}
+
+func TestMaxBatch(t *testing.T) {
+	c := Character{CharacterSchema: client.CharacterSchema{
+		InventoryMaxItems: 100,
+		Inventory:         &[]client.InventorySlot{{Slot: 1}, {Slot: 2}, {Slot: 3}},
+	}}
+
+	tests := []struct {
+		name      string
+		materials SimpleItems
+		yield     int
+		expected  int
+	}{
+		{"single material", SimpleItems{{Code: "copper_ore", Quantity: 8}}, 1, 12},
+		{"two materials", SimpleItems{{Code: "copper", Quantity: 6}, {Code: "ash_plank", Quantity: 4}}, 1, 10},
+		{"yield larger than materials", SimpleItems{{Code: "raw_gudgeon", Quantity: 1}}, 5, 20},
+		{"too many distinct materials", SimpleItems{{Code: "a", Quantity: 1}, {Code: "b", Quantity: 1}, {Code: "c", Quantity: 1}}, 1, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, c.MaxBatch(tt.materials, tt.yield))
+		})
+	}
+
+	// kept items reduce the free space
+	(*c.Inventory)[0] = client.InventorySlot{Slot: 1, Code: "cooked_chicken", Quantity: 20}
+	assert.Equal(t, 10, c.MaxBatch(SimpleItems{{Code: "copper_ore", Quantity: 8}}, 1))
+	assert.Equal(t, 0, c.MaxBatch(SimpleItems{{Code: "copper", Quantity: 6}, {Code: "ash_plank", Quantity: 4}}, 1))
+}
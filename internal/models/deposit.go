@@ -0,0 +1,86 @@
+package models
+
+import (
+	"cmp"
+	"slices"
+)
+
+// Space is an amount of inventory space, in items and slots
+type Space struct {
+	Items int
+	Slots int
+}
+
+// DepositPolicy configures which items a character keeps when depositing
+type DepositPolicy struct {
+	// Keep is the quantity of each item the character keeps in their inventory
+	Keep map[string]int `mapstructure:"keep"`
+	// Always lists items which are always deposited in full, even if kept
+	Always []string `mapstructure:"always"`
+	// Minimal deposits only what blocks the next action when banking, rather than everything
+	Minimal bool `mapstructure:"minimal"`
+}
+
+// Depositable returns every item in the character's inventory which should be deposited,
+// one deposit per item code. Each deposit costs a cooldown, so the largest are first.
+func (p DepositPolicy) Depositable(c Character) SimpleItems {
+	totals := make(map[string]int)
+	for _, slot := range *c.Inventory {
+		if slot.Code != "" && slot.Quantity > 0 {
+			totals[slot.Code] += slot.Quantity
+		}
+	}
+
+	var deposits SimpleItems
+	for code, qty := range totals {
+		if !slices.Contains(p.Always, code) {
+			qty -= p.Keep[code]
+		}
+		if qty > 0 {
+			deposits = append(deposits, SimpleItem{Code: code, Quantity: qty})
+		}
+	}
+	slices.SortFunc(deposits, func(a, b SimpleItem) int {
+		if c := cmp.Compare(b.Quantity, a.Quantity); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Code, b.Code)
+	})
+	return deposits
+}
+
+// PlanRoom returns the deposits to make room for the next action. Unless the policy is minimal
+// this is everything depositable, otherwise the largest deposits are made until the needed space
+// is free, along with any items which are always deposited.
+func (p DepositPolicy) PlanRoom(c Character, need Space) SimpleItems {
+	deposits := p.Depositable(c)
+	if !p.Minimal {
+		return deposits
+	}
+
+	free := c.FreeSpace()
+	var planned SimpleItems
+	for _, d := range deposits {
+		enough := free.Items >= need.Items && free.Slots >= need.Slots
+		if enough && !slices.Contains(p.Always, d.Code) {
+			continue
+		}
+		planned = append(planned, d)
+		free.Items += d.Quantity
+		if d.Quantity == c.CountInventoryItem(d.Code) {
+			free.Slots += slotsUsed(c, d.Code)
+		}
+	}
+	return planned
+}
+
+// slotsUsed returns the number of inventory slots holding the item
+func slotsUsed(c Character, code string) int {
+	var n int
+	for _, slot := range *c.Inventory {
+		if slot.Code == code {
+			n++
+		}
+	}
+	return n
+}
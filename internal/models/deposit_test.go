@@ -0,0 +1,43 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/promiseofcake/artifactsmmo-go-client/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDepositable(t *testing.T) {
+	c := Character{CharacterSchema: client.CharacterSchema{
+		InventoryMaxItems: 100,
+		Inventory: &[]client.InventorySlot{
+			{Slot: 1, Code: "copper_ore", Quantity: 20},
+			{Slot: 2, Code: "cooked_chicken", Quantity: 10},
+			{Slot: 3, Code: "copper_ore", Quantity: 30},
+			{Slot: 4, Code: "iron_pickaxe", Quantity: 1},
+			{Slot: 5, Code: "feather", Quantity: 4},
+			{Slot: 6},
+		},
+	}}
+
+	p := DepositPolicy{
+		Keep:   map[string]int{"cooked_chicken": 5, "iron_pickaxe": 1, "feather": 2},
+		Always: []string{"feather"},
+	}
+	assert.Equal(t, SimpleItems{
+		{Code: "copper_ore", Quantity: 50},
+		{Code: "cooked_chicken", Quantity: 5},
+		{Code: "feather", Quantity: 4},
+	}, p.Depositable(c))
+
+	// not minimal, everything is deposited
+	assert.Equal(t, p.Depositable(c), p.PlanRoom(c, Space{Items: 1}))
+
+	// minimal deposits the largest first until there is room, and always deposits
+	p.Minimal = true
+	assert.Equal(t, SimpleItems{
+		{Code: "copper_ore", Quantity: 50},
+		{Code: "feather", Quantity: 4},
+	}, p.PlanRoom(c, Space{Items: 50, Slots: 2}))
+	assert.Equal(t, SimpleItems{{Code: "feather", Quantity: 4}}, p.PlanRoom(c, Space{Items: 1, Slots: 1}))
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"cmp"
+	"slices"
+	"time"
+)
+
+// Events are the active game events
+type Events []Event
+
+// Event is a timed game event, which spawns a special monster or resource at a map location
+type Event struct {
+	Name       string
+	Location   Location
+	Expiration time.Time
+}
+
+// Expired determines if the event has ended
+func (e Event) Expired(now time.Time) bool {
+	return !now.Before(e.Expiration)
+}
+
+// EventInterests configures the event monsters and resources a character leaves their
+// current operation for
+type EventInterests struct {
+	Monsters  []string `mapstructure:"monsters"`
+	Resources []string `mapstructure:"resources"`
+}
+
+// Matching returns the active events spawning a monster or resource of interest, those
+// expiring soonest first
+func (i EventInterests) Matching(events Events, now time.Time) Events {
+	var matching Events
+	for _, e := range events {
+		if e.Expired(now) {
+			continue
+		}
+		switch e.Location.Type {
+		case "monster":
+			if !slices.Contains(i.Monsters, e.Location.Code) {
+				continue
+			}
+		case "resource":
+			if !slices.Contains(i.Resources, e.Location.Code) {
+				continue
+			}
+		default:
+			continue
+		}
+		matching = append(matching, e)
+	}
+	slices.SortStableFunc(matching, func(a, b Event) int {
+		return cmp.Compare(a.Expiration.UnixNano(), b.Expiration.UnixNano())
+	})
+	return matching
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventInterestsMatching(t *testing.T) {
+	now := time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC)
+	bandit := Event{
+		Name:       "Bandit Camp",
+		Location:   Location{Code: "bandit_lizard", Type: "monster"},
+		Expiration: now.Add(time.Hour),
+	}
+	strange := Event{
+		Name:       "Strange Apparition",
+		Location:   Location{Code: "strange_rocks", Type: "resource"},
+		Expiration: now.Add(10 * time.Minute),
+	}
+	expired := Event{
+		Name:       "Magic Apparition",
+		Location:   Location{Code: "magic_tree", Type: "resource"},
+		Expiration: now,
+	}
+	merchant := Event{
+		Name:       "Nomadic Merchant",
+		Location:   Location{Code: "nomadic_merchant", Type: "npc"},
+		Expiration: now.Add(time.Hour),
+	}
+	events := Events{bandit, strange, expired, merchant}
+
+	assert.Empty(t, EventInterests{}.Matching(events, now))
+	assert.Equal(t, Events{bandit}, EventInterests{Monsters: []string{"bandit_lizard", "strange_rocks"}}.Matching(events, now))
+	assert.Equal(t, Events{strange, bandit}, EventInterests{
+		Monsters:  []string{"bandit_lizard"},
+		Resources: []string{"strange_rocks", "magic_tree", "nomadic_merchant"},
+	}.Matching(events, now))
+	assert.Empty(t, EventInterests{Monsters: []string{"bandit_lizard"}}.Matching(events, now.Add(2*time.Hour)))
+}
@@ -0,0 +1,93 @@
+package models
+
+import (
+	"cmp"
+	"slices"
+)
+
+// DefaultMinHPRatio is the HP ratio below which a character heals, when not configured
+const DefaultMinHPRatio = 0.5
+
+// HealthPolicy configures when and how a character heals before engaging in a fight
+type HealthPolicy struct {
+	// MinRatio is the HP ratio (0-1) below which the character heals, 0.5 if unset
+	MinRatio float64 `mapstructure:"min_hp_ratio"`
+	// WithdrawFood allows food to be withdrawn from the bank when none is carried
+	WithdrawFood bool `mapstructure:"withdraw_food"`
+}
+
+// NeedsHeal determines if the character's HP is below the policy ratio
+func (p HealthPolicy) NeedsHeal(c Character) bool {
+	ratio := p.MinRatio
+	if ratio <= 0 {
+		ratio = DefaultMinHPRatio
+	}
+	if c.MaxHp == 0 {
+		return false
+	}
+	return float64(c.Hp)/float64(c.MaxHp) < ratio
+}
+
+// MissingHP returns the HP the character needs to be fully healed
+func (c Character) MissingHP() int {
+	return max(0, c.MaxHp-c.Hp)
+}
+
+// Food is a consumable which restores HP
+type Food struct {
+	Code     string
+	Heal     int
+	Level    int
+	Quantity int
+}
+
+// FoodFromItem returns the item as Food if it is a consumable which heals
+func FoodFromItem(item Item, quantity int) (Food, bool) {
+	if item.Type != "consumable" || item.Effects == nil {
+		return Food{}, false
+	}
+	for _, e := range *item.Effects {
+		if e.Name == "heal" && e.Value > 0 {
+			return Food{Code: item.Code, Heal: e.Value, Level: item.Level, Quantity: quantity}, true
+		}
+	}
+	return Food{}, false
+}
+
+// PlanMeals chooses the food to eat to restore the missing HP, food above the character
+// level is skipped. The largest heals are eaten first without overhealing, then the smallest
+// remaining heal tops up. If there isn't enough food, everything usable is eaten.
+func PlanMeals(foods []Food, level int, missing int) SimpleItems {
+	var usable []Food
+	for _, f := range foods {
+		if f.Level <= level && f.Quantity > 0 && f.Heal > 0 {
+			usable = append(usable, f)
+		}
+	}
+	slices.SortFunc(usable, func(a, b Food) int {
+		return cmp.Compare(b.Heal, a.Heal)
+	})
+
+	eaten := make(map[string]int)
+	for _, f := range usable {
+		n := min(f.Quantity, missing/f.Heal)
+		eaten[f.Code] += n
+		missing -= n * f.Heal
+	}
+
+	// top up with the smallest heal remaining
+	for i := len(usable) - 1; i >= 0 && missing > 0; i-- {
+		f := usable[i]
+		n := min(f.Quantity-eaten[f.Code], (missing+f.Heal-1)/f.Heal)
+		eaten[f.Code] += n
+		missing -= n * f.Heal
+	}
+
+	var meals SimpleItems
+	for _, f := range usable {
+		if eaten[f.Code] > 0 {
+			meals = append(meals, SimpleItem{Code: f.Code, Quantity: eaten[f.Code]})
+		}
+	}
+	return meals
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/promiseofcake/artifactsmmo-go-client/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNeedsHeal(t *testing.T) {
+	c := Character{CharacterSchema: client.CharacterSchema{Hp: 40, MaxHp: 100}}
+
+	assert.True(t, HealthPolicy{}.NeedsHeal(c))
+	assert.False(t, HealthPolicy{MinRatio: 0.3}.NeedsHeal(c))
+	assert.False(t, HealthPolicy{}.NeedsHeal(Character{}))
+}
+
+func TestPlanMeals(t *testing.T) {
+	foods := []Food{
+		{Code: "cooked_chicken", Heal: 75, Level: 1, Quantity: 2},
+		{Code: "cooked_gudgeon", Heal: 25, Level: 1, Quantity: 10},
+		{Code: "cooked_trout", Heal: 150, Level: 20, Quantity: 5},
+	}
+
+	tests := []struct {
+		name     string
+		missing  int
+		expected SimpleItems
+	}{
+		{"exact", 100, SimpleItems{{Code: "cooked_chicken", Quantity: 1}, {Code: "cooked_gudgeon", Quantity: 1}}},
+		{"top up", 80, SimpleItems{{Code: "cooked_chicken", Quantity: 1}, {Code: "cooked_gudgeon", Quantity: 1}}},
+		{"small", 10, SimpleItems{{Code: "cooked_gudgeon", Quantity: 1}}},
+		{"not enough", 1000, SimpleItems{{Code: "cooked_chicken", Quantity: 2}, {Code: "cooked_gudgeon", Quantity: 10}}},
+		{"full", 0, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, PlanMeals(foods, 10, tt.missing))
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package models
+
+import (
+	"sync"
+	"time"
+)
+
+// SkillHistory records the experience earned, and time spent, per character skill
+// along with the last skill each character trained
+type SkillHistory struct {
+	mu      sync.Mutex
+	xp      map[string]int
+	spent   map[string]time.Duration
+	actions map[string]int
+	last    map[string]string
+}
+
+// NewSkillHistory returns an empty SkillHistory
+func NewSkillHistory() *SkillHistory {
+	return &SkillHistory{
+		xp:      make(map[string]int),
+		spent:   make(map[string]time.Duration),
+		actions: make(map[string]int),
+		last:    make(map[string]string),
+	}
+}
+
+func skillHistoryPK(character, skill string) string {
+	return character + "|" + skill
+}
+
+// Record adds the experience earned by an action, and its cooldown
+func (h *SkillHistory) Record(character, skill string, xp int, cooldown time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	key := skillHistoryPK(character, skill)
+	h.xp[key] += xp
+	h.spent[key] += cooldown
+	h.actions[key]++
+	h.last[character] = skill
+}
+
+// Last returns the last skill the character trained
+func (h *SkillHistory) Last(character string) string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.last[character]
+}
+
+// XPPerHour returns the observed experience rate for the character's skill,
+// false is returned if there is no history
+func (h *SkillHistory) XPPerHour(character, skill string) (float64, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	key := skillHistoryPK(character, skill)
+	spent, ok := h.spent[key]
+	if !ok || spent <= 0 {
+		return 0, false
+	}
+	return float64(h.xp[key]) / spent.Hours(), true
+}
+
+// Cooldown returns the average cooldown of an action of the character's skill, such as a gather
+// or a batch of crafts, false is returned if there is no history
+func (h *SkillHistory) Cooldown(character, skill string) (time.Duration, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	key := skillHistoryPK(character, skill)
+	n := h.actions[key]
+	if n == 0 {
+		return 0, false
+	}
+	return h.spent[key] / time.Duration(n), true
+}
+
+// GatherStats are the accumulated results of gathering a resource
+type GatherStats struct {
+	Gathers  int
+	XP       int
+	Items    int
+	Cooldown time.Duration
+}
+
+// GatherHistory records the results of gathering, per character resource
+type GatherHistory struct {
+	mu    sync.Mutex
+	stats map[string]GatherStats
+}
+
+// NewGatherHistory returns an empty GatherHistory
+func NewGatherHistory() *GatherHistory {
+	return &GatherHistory{
+		stats: make(map[string]GatherStats),
+	}
+}
+
+// Record adds the result of a single gather
+func (h *GatherHistory) Record(character, resource string, xp, items int, cooldown time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	key := character + "|" + resource
+	s := h.stats[key]
+	s.Gathers++
+	s.XP += xp
+	s.Items += items
+	s.Cooldown += cooldown
+	h.stats[key] = s
+}
+
+// Get returns the accumulated results for the character resource, false is
+// returned if there is no history
+func (h *GatherHistory) Get(character, resource string) (GatherStats, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.stats[character+"|"+resource]
+	return s, ok
+}
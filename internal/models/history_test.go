@@ -0,0 +1,45 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSkillHistory(t *testing.T) {
+	h := NewSkillHistory()
+	_, ok := h.XPPerHour("Milnor", "mining")
+	assert.False(t, ok)
+
+	h.Record("Milnor", "mining", 100, 30*time.Minute)
+	h.Record("Milnor", "mining", 50, 30*time.Minute)
+	h.Record("Milnor", "fishing", 10, time.Minute)
+
+	rate, ok := h.XPPerHour("Milnor", "mining")
+	assert.True(t, ok)
+	assert.Equal(t, 150.0, rate)
+	assert.Equal(t, "fishing", h.Last("Milnor"))
+
+	cooldown, ok := h.Cooldown("Milnor", "mining")
+	assert.True(t, ok)
+	assert.Equal(t, 30*time.Minute, cooldown)
+	_, ok = h.Cooldown("Milnor", "cooking")
+	assert.False(t, ok)
+}
+
+func TestGatherHistory(t *testing.T) {
+	h := NewGatherHistory()
+	_, ok := h.Get("Milnor", "copper_rocks")
+	assert.False(t, ok)
+
+	h.Record("Milnor", "copper_rocks", 10, 1, 20*time.Second)
+	h.Record("Milnor", "copper_rocks", 12, 2, 22*time.Second)
+
+	stats, ok := h.Get("Milnor", "copper_rocks")
+	assert.True(t, ok)
+	assert.Equal(t, GatherStats{Gathers: 2, XP: 22, Items: 3, Cooldown: 42 * time.Second}, stats)
+
+	_, ok = h.Get("Bilnor", "copper_rocks")
+	assert.False(t, ok)
+}
@@ -4,6 +4,17 @@ import "github.com/promiseofcake/artifactsmmo-go-client/client"
 
 type SimpleItems []SimpleItem
 
+// Count returns the total quantity of the given item code
+func (s SimpleItems) Count(code string) int {
+	var count int
+	for _, i := range s {
+		if i.Code == code {
+			count += i.Quantity
+		}
+	}
+	return count
+}
+
 type SimpleItem struct {
 	Code     string `json:"code"`
 	Quantity int    `json:"quantity"`
@@ -22,3 +33,15 @@ type CraftResource struct {
 	CostPerResource int
 	Available       int
 }
+
+// Missing returns the materials the bank lacks to craft the item the given number of times
+func (i Item) Missing(bank SimpleItems, crafts int) SimpleItems {
+	var missing SimpleItems
+	for _, mat := range i.CraftMaterials {
+		qty := mat.CostPerResource*crafts - bank.Count(mat.RequiredCode)
+		if qty > 0 {
+			missing = append(missing, SimpleItem{Code: mat.RequiredCode, Quantity: qty})
+		}
+	}
+	return missing
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItemMissing(t *testing.T) {
+	item := Item{CraftMaterials: []*CraftResource{
+		{RequiredCode: "copper", CostPerResource: 6},
+		{RequiredCode: "feather", CostPerResource: 2},
+		{RequiredCode: "ash_plank", CostPerResource: 1},
+	}}
+	bank := SimpleItems{
+		{Code: "copper", Quantity: 50},
+		{Code: "feather", Quantity: 3},
+	}
+
+	assert.Empty(t, Item{}.Missing(bank, 5))
+	assert.Empty(t, item.Missing(SimpleItems{
+		{Code: "copper", Quantity: 6},
+		{Code: "feather", Quantity: 2},
+		{Code: "ash_plank", Quantity: 1},
+	}, 1))
+	assert.Equal(t, SimpleItems{
+		{Code: "feather", Quantity: 7},
+		{Code: "ash_plank", Quantity: 5},
+	}, item.Missing(bank, 5))
+	assert.Equal(t, SimpleItems{
+		{Code: "copper", Quantity: 10},
+		{Code: "feather", Quantity: 17},
+		{Code: "ash_plank", Quantity: 10},
+	}, item.Missing(bank, 10))
+}
@@ -1,5 +1,7 @@
 package models
 
+import "math"
+
 type LocationMap map[string]Location
 type Locations []Location
 type Location struct {
@@ -14,6 +16,25 @@ func locationPK(loc Location) string {
 	return loc.Type + "|" + loc.Code
 }
 
+// Nearest returns the closest location with the given code to the given coords
+func (l Locations) Nearest(code string, from Coords) (Location, bool) {
+	var nearest Location
+	found := false
+	distance := math.MaxInt
+	for _, loc := range l {
+		if loc.Code != code {
+			continue
+		}
+		d := CalculateDistance(loc.Coords, from)
+		if d < distance {
+			distance = d
+			nearest = loc
+			found = true
+		}
+	}
+	return nearest, found
+}
+
 func LocationsToMap(locs Locations) LocationMap {
 	locationMap := make(LocationMap)
 	for _, l := range locs {
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocationsNearest(t *testing.T) {
+	locs := Locations{
+		{Code: "bank", Coords: Coords{4, 1}},
+		{Code: "bank", Coords: Coords{-2, 0}},
+		{Code: "copper_rocks", Coords: Coords{0, 0}},
+	}
+
+	nearest, found := locs.Nearest("bank", Coords{0, 0})
+	assert.True(t, found)
+	assert.Equal(t, Coords{-2, 0}, nearest.Coords)
+
+	nearest, found = locs.Nearest("bank", Coords{3, 3})
+	assert.True(t, found)
+	assert.Equal(t, Coords{4, 1}, nearest.Coords)
+
+	_, found = locs.Nearest("iron_rocks", Coords{0, 0})
+	assert.False(t, found)
+}
@@ -1,7 +1,105 @@
 package models
 
+import (
+	"slices"
+	"time"
+)
+
+// Order actions, the source an order is fulfilled from
+const (
+	OrderGather = "gather"
+	OrderFight  = "fight"
+	OrderCraft  = "craft"
+	OrderBuy    = "buy"
+)
+
+// IsOrderAction determines if the given name is a known order action, empty detects the
+// action from the item
+func IsOrderAction(name string) bool {
+	switch name {
+	case "", OrderGather, OrderFight, OrderCraft, OrderBuy:
+		return true
+	}
+	return false
+}
+
 type Order struct {
 	Item        SimpleItem `json:"item"`
 	Concurrency int        `json:"concurrency"`
-	Action      string     `json:"action"`
+	// Action overrides the source the order is fulfilled from, detected from the item if empty
+	Action string `json:"action"`
+	// Priority orders are picked highest first
+	Priority int `json:"priority"`
+	// Deadline, if set, drops the order once passed
+	Deadline time.Time `json:"deadline"`
+	// Maintain keeps the order after it is fulfilled, and re-activates it when
+	// the bank stock drops below Threshold (or the quantity if unset)
+	Maintain  bool `json:"maintain"`
+	Threshold int  `json:"threshold"`
+	// Characters, if set, restricts which characters may fulfil the order
+	Characters []string `json:"characters"`
+	// Skill and Level, if set, restrict the order to characters with at least Level in Skill
+	Skill string `json:"skill"`
+	Level int    `json:"level"`
+	// ID identifies a queued order, and For the order this input order is producing for, both
+	// are set by the engine
+	ID  int `json:"-" mapstructure:"-"`
+	For int `json:"-" mapstructure:"-"`
+}
+
+// Equal determines if two orders are the same
+func (o Order) Equal(p Order) bool {
+	return o.Item == p.Item &&
+		o.Concurrency == p.Concurrency &&
+		o.Action == p.Action &&
+		o.Priority == p.Priority &&
+		o.Deadline.Equal(p.Deadline) &&
+		o.Maintain == p.Maintain &&
+		o.Threshold == p.Threshold &&
+		slices.Equal(o.Characters, p.Characters) &&
+		o.Skill == p.Skill &&
+		o.Level == p.Level &&
+		o.For == p.For
+}
+
+// Expired determines if the order's deadline has passed
+func (o Order) Expired(now time.Time) bool {
+	return !o.Deadline.IsZero() && now.After(o.Deadline)
+}
+
+// EligibleFor determines if the character may fulfil the order
+func (o Order) EligibleFor(c Character) bool {
+	if len(o.Characters) > 0 && !slices.Contains(o.Characters, c.Name) {
+		return false
+	}
+	if o.Skill != "" && c.GetSkillLevel(o.Skill) < o.Level {
+		return false
+	}
+	return true
+}
+
+// Reactivates determines if a fulfilled maintain order should be active again,
+// given the quantity in stock
+func (o Order) Reactivates(stock int) bool {
+	threshold := o.Threshold
+	if threshold <= 0 {
+		threshold = o.Item.Quantity
+	}
+	return o.Maintain && stock < threshold
+}
+
+// Before determines if the order should be picked before another, by highest
+// priority then the earliest deadline
+func (o Order) Before(p Order) bool {
+	if o.Priority != p.Priority {
+		return o.Priority > p.Priority
+	}
+	switch {
+	case o.Deadline.IsZero():
+		return false
+	case p.Deadline.IsZero():
+		return true
+	default:
+		return o.Deadline.Before(p.Deadline)
+	}
 }
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/promiseofcake/artifactsmmo-go-client/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderBefore(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name     string
+		o, p     Order
+		expected bool
+	}{
+		{"higher priority", Order{Priority: 2}, Order{Priority: 1}, true},
+		{"lower priority", Order{Priority: 1}, Order{Priority: 2, Deadline: now}, false},
+		{"earlier deadline", Order{Deadline: now}, Order{Deadline: now.Add(time.Hour)}, true},
+		{"deadline before none", Order{Deadline: now}, Order{}, true},
+		{"none after deadline", Order{}, Order{Deadline: now}, false},
+		{"equal", Order{}, Order{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.o.Before(tt.p))
+		})
+	}
+}
+
+func TestOrderEligibleFor(t *testing.T) {
+	c := Character{CharacterSchema: client.CharacterSchema{Name: "Milnor", MiningLevel: 5}}
+
+	tests := []struct {
+		name     string
+		order    Order
+		expected bool
+	}{
+		{"anyone", Order{}, true},
+		{"assigned", Order{Characters: []string{"Bilnor", "Milnor"}}, true},
+		{"assigned elsewhere", Order{Characters: []string{"Bilnor"}}, false},
+		{"skilled", Order{Skill: "mining", Level: 5}, true},
+		{"unskilled", Order{Skill: "mining", Level: 10}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.order.EligibleFor(c))
+		})
+	}
+}
+
+func TestOrderReactivates(t *testing.T) {
+	o := Order{Item: SimpleItem{Code: "copper", Quantity: 100}, Maintain: true}
+	assert.True(t, o.Reactivates(99))
+	assert.False(t, o.Reactivates(100))
+
+	o.Threshold = 50
+	assert.False(t, o.Reactivates(60))
+	assert.True(t, o.Reactivates(49))
+
+	o.Maintain = false
+	assert.False(t, o.Reactivates(0))
+}
+
+func TestOrderEqual(t *testing.T) {
+	o := Order{Item: SimpleItem{Code: "copper", Quantity: 1}, Characters: []string{"Milnor"}}
+	assert.True(t, o.Equal(o))
+
+	p := o
+	p.Characters = []string{"Bilnor"}
+	assert.False(t, o.Equal(p))
+}
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// PlanStep is a single simulated step of an order plan
+type PlanStep struct {
+	Depth    int
+	Action   string
+	Code     string
+	Quantity int
+	Target   string
+	Actions  int
+	Duration time.Duration
+}
+
+// Plan is the simulated sequence of steps to fulfil a set of orders
+type Plan struct {
+	Steps []PlanStep
+}
+
+// Actions returns the total number of actions in the Plan
+func (p Plan) Actions() int {
+	var total int
+	for _, s := range p.Steps {
+		total += s.Actions
+	}
+	return total
+}
+
+// Duration returns the total estimated cooldown time for the Plan
+func (p Plan) Duration() time.Duration {
+	var total time.Duration
+	for _, s := range p.Steps {
+		total += s.Duration
+	}
+	return total
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanTotals(t *testing.T) {
+	p := Plan{Steps: []PlanStep{
+		{Action: "gather", Actions: 36, Duration: 15 * time.Minute},
+		{Action: "skip"},
+		{Action: "craft", Actions: 5, Duration: 40 * time.Second},
+	}}
+
+	assert.Equal(t, 41, p.Actions())
+	assert.Equal(t, 15*time.Minute+40*time.Second, p.Duration())
+}
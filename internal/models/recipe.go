@@ -0,0 +1,65 @@
+package models
+
+import (
+	"cmp"
+	"slices"
+)
+
+// Recipe is a node in the crafting tree for an item. Raw materials have no Skill
+// and no Inputs, and are instead sourced from Resources or Monsters.
+type Recipe struct {
+	Code      string
+	Name      string
+	Quantity  int
+	Skill     string
+	Level     int
+	Yield     int
+	Banked    int
+	Resources Resources
+	Monsters  Monsters
+	Inputs    []*Recipe
+}
+
+// IsRaw determines if the Recipe is a raw material, rather than a craft
+func (r *Recipe) IsRaw() bool {
+	return r.Skill == ""
+}
+
+// Crafts returns the number of crafts required to produce the Recipe quantity
+func (r *Recipe) Crafts() int {
+	if r.Yield <= 1 {
+		return r.Quantity
+	}
+	return (r.Quantity + r.Yield - 1) / r.Yield
+}
+
+// RawMaterials returns the total quantity of every raw material in the Recipe tree,
+// sorted by code
+func (r *Recipe) RawMaterials() SimpleItems {
+	totals := make(map[string]int)
+	var walk func(node *Recipe)
+	walk = func(node *Recipe) {
+		if node.IsRaw() {
+			totals[node.Code] += node.Quantity
+			return
+		}
+		for _, input := range node.Inputs {
+			walk(input)
+		}
+	}
+	walk(r)
+
+	var materials SimpleItems
+	for code, qty := range totals {
+		materials = append(materials, SimpleItem{Code: code, Quantity: qty})
+	}
+	slices.SortFunc(materials, func(a, b SimpleItem) int {
+		return cmp.Compare(a.Code, b.Code)
+	})
+	return materials
+}
+
+// CanCraft determines if the Character has the skill level to craft the Recipe
+func (r *Recipe) CanCraft(c Character) bool {
+	return !r.IsRaw() && c.GetSkillLevel(r.Skill) >= r.Level
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/promiseofcake/artifactsmmo-go-client/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecipeRawMaterials(t *testing.T) {
+	recipe := &Recipe{
+		Code:     "copper_dagger",
+		Quantity: 2,
+		Skill:    "weaponcrafting",
+		Level:    1,
+		Inputs: []*Recipe{
+			{
+				Code:     "copper",
+				Quantity: 12,
+				Skill:    "mining",
+				Level:    1,
+				Inputs: []*Recipe{
+					{Code: "copper_ore", Quantity: 72},
+				},
+			},
+			{Code: "feather", Quantity: 4},
+			{Code: "copper_ore", Quantity: 8},
+		},
+	}
+
+	expected := SimpleItems{
+		{Code: "copper_ore", Quantity: 80},
+		{Code: "feather", Quantity: 4},
+	}
+	assert.Equal(t, expected, recipe.RawMaterials())
+}
+
+func TestRecipeCrafts(t *testing.T) {
+	tests := []struct {
+		name     string
+		recipe   Recipe
+		expected int
+	}{
+		{"no yield", Recipe{Quantity: 5}, 5},
+		{"single yield", Recipe{Quantity: 5, Yield: 1}, 5},
+		{"exact yield", Recipe{Quantity: 10, Yield: 5}, 2},
+		{"partial yield", Recipe{Quantity: 11, Yield: 5}, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.recipe.Crafts())
+		})
+	}
+}
+
+func TestRecipeCanCraft(t *testing.T) {
+	c := Character{CharacterSchema: client.CharacterSchema{WeaponcraftingLevel: 5}}
+
+	assert.True(t, (&Recipe{Skill: "weaponcrafting", Level: 5}).CanCraft(c))
+	assert.False(t, (&Recipe{Skill: "weaponcrafting", Level: 6}).CanCraft(c))
+	assert.False(t, (&Recipe{Code: "copper_ore"}).CanCraft(c))
+}
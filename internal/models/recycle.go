@@ -0,0 +1,31 @@
+package models
+
+import (
+	"cmp"
+	"slices"
+)
+
+// RecyclePolicy configures which surplus items a character recycles from the bank
+type RecyclePolicy struct {
+	// Items maps each recyclable item code to the quantity kept in the bank
+	Items map[string]int `mapstructure:"items"`
+}
+
+// Surplus returns the quantity of each recyclable bank item above its keep threshold,
+// the largest first
+func (p RecyclePolicy) Surplus(bank SimpleItems) SimpleItems {
+	var surplus SimpleItems
+	for code, keep := range p.Items {
+		qty := bank.Count(code) - keep
+		if qty > 0 {
+			surplus = append(surplus, SimpleItem{Code: code, Quantity: qty})
+		}
+	}
+	slices.SortFunc(surplus, func(a, b SimpleItem) int {
+		if a.Quantity != b.Quantity {
+			return cmp.Compare(b.Quantity, a.Quantity)
+		}
+		return cmp.Compare(a.Code, b.Code)
+	})
+	return surplus
+}
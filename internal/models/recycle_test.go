@@ -0,0 +1,29 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecycleSurplus(t *testing.T) {
+	bank := SimpleItems{
+		{Code: "copper_dagger", Quantity: 12},
+		{Code: "wooden_staff", Quantity: 30},
+		{Code: "copper_ring", Quantity: 2},
+		{Code: "copper", Quantity: 200},
+	}
+
+	p := RecyclePolicy{Items: map[string]int{
+		"copper_dagger": 2,
+		"wooden_staff":  0,
+		"copper_ring":   5,
+		"copper_boots":  0,
+	}}
+	assert.Equal(t, SimpleItems{
+		{Code: "wooden_staff", Quantity: 30},
+		{Code: "copper_dagger", Quantity: 10},
+	}, p.Surplus(bank))
+
+	assert.Empty(t, RecyclePolicy{}.Surplus(bank))
+}
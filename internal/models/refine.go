@@ -0,0 +1,49 @@
+package models
+
+import (
+	"cmp"
+	"slices"
+)
+
+// Refine metrics, used to choose between the items a character can refine
+const (
+	RefineByXP     = "xp"
+	RefineByDemand = "demand"
+	RefineByPrice  = "price"
+)
+
+// RefineOption is an item which can be refined from the bank stock
+type RefineOption struct {
+	Item *Item
+	// Sets is the number of crafts possible from the bank in one batch
+	Sets int
+	// Demand is the quantity wanted by orders, less the quantity already banked
+	Demand int
+	// Price is the sell price of a single item
+	Price int
+}
+
+// ChooseRefine returns the best option by the metric, xp prefers the highest level item,
+// demand the item most wanted by orders and price the most valuable batch. Ties, and options
+// with no demand or price, fall back to the highest level. False is returned if there are no options.
+func ChooseRefine(options []RefineOption, metric string) (RefineOption, bool) {
+	if len(options) == 0 {
+		return RefineOption{}, false
+	}
+
+	sorted := slices.Clone(options)
+	slices.SortStableFunc(sorted, func(a, b RefineOption) int {
+		var c int
+		switch metric {
+		case RefineByDemand:
+			c = cmp.Compare(b.Demand, a.Demand)
+		case RefineByPrice:
+			c = cmp.Compare(b.Price*b.Sets, a.Price*a.Sets)
+		}
+		if c != 0 {
+			return c
+		}
+		return cmp.Compare(b.Item.Level, a.Item.Level)
+	})
+	return sorted[0], true
+}
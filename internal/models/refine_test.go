@@ -0,0 +1,45 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/promiseofcake/artifactsmmo-go-client/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChooseRefine(t *testing.T) {
+	option := func(code string, level, sets, demand, price int) RefineOption {
+		return RefineOption{
+			Item:   &Item{ItemSchema: client.ItemSchema{Code: code, Level: level}},
+			Sets:   sets,
+			Demand: demand,
+			Price:  price,
+		}
+	}
+	options := []RefineOption{
+		option("copper", 1, 10, 0, 5),
+		option("iron", 10, 2, 0, 8),
+		option("ash_plank", 1, 20, 30, 1),
+	}
+
+	tests := []struct {
+		metric   string
+		expected string
+	}{
+		{RefineByXP, "iron"},
+		{RefineByDemand, "ash_plank"},
+		{RefineByPrice, "copper"},
+		{"", "iron"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.metric, func(t *testing.T) {
+			o, ok := ChooseRefine(options, tt.metric)
+			assert.True(t, ok)
+			assert.Equal(t, tt.expected, o.Item.Code)
+		})
+	}
+
+	_, ok := ChooseRefine(nil, RefineByXP)
+	assert.False(t, ok)
+}
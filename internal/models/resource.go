@@ -1,6 +1,10 @@
 package models
 
-import "github.com/promiseofcake/artifactsmmo-go-client/client"
+import (
+	"slices"
+
+	"github.com/promiseofcake/artifactsmmo-go-client/client"
+)
 
 type ResourceMap map[string]*Resource
 type Resources []Resource
@@ -13,6 +17,20 @@ type Resource struct {
 	Skill    client.ResourceSchemaSkill `json:"skill"`
 	Level    int                        `json:"level"`
 	Location Location                   `json:"location"`
+	Drops    []string                   `json:"drops"`
+}
+
+// OnlyDrops determines if every item the resource drops is one of the given codes
+func (r Resource) OnlyDrops(codes []string) bool {
+	if len(r.Drops) == 0 {
+		return false
+	}
+	for _, d := range r.Drops {
+		if !slices.Contains(codes, d) {
+			return false
+		}
+	}
+	return true
 }
 
 // GetCoords returns a Resources's coords
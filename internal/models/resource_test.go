@@ -0,0 +1,16 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResourceOnlyDrops(t *testing.T) {
+	ash := Resource{Code: "ash_tree", Drops: []string{"ash_wood", "sap"}}
+
+	assert.True(t, ash.OnlyDrops([]string{"sap", "ash_wood", "feather"}))
+	assert.False(t, ash.OnlyDrops([]string{"ash_wood"}))
+	assert.False(t, ash.OnlyDrops(nil))
+	assert.False(t, Resource{Code: "copper_rocks"}.OnlyDrops([]string{"copper_ore"}))
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Routine is a schedule of steps a character works through in order, in place of
+// round-robin over their actions
+type Routine struct {
+	Steps []RoutineStep `mapstructure:"steps"`
+	// Repeat starts the routine over after the last step, otherwise the character
+	// returns to their actions
+	Repeat bool `mapstructure:"repeat"`
+}
+
+// RoutineStep is an action run until any of its stop conditions is met, a step without
+// stop conditions runs until its window closes, or indefinitely
+type RoutineStep struct {
+	Action string `mapstructure:"action"`
+	// Duration is how long the step runs for
+	Duration time.Duration `mapstructure:"duration"`
+	// Count is the number of actions, such as fights or gathers, the step performs
+	Count int `mapstructure:"count"`
+	// Level is the level target for the skill, the combat level if no skill is given
+	Level int    `mapstructure:"level"`
+	Skill string `mapstructure:"skill"`
+	// Window is the local time of day the step runs in, such as "18:00-02:00", any time if empty
+	Window string `mapstructure:"window"`
+}
+
+// Done determines if a stop condition of the step is met, given the character's live state, when the
+// step started, and the number of actions performed since
+func (s RoutineStep) Done(c Character, started time.Time, count int, now time.Time) bool {
+	if s.Duration > 0 && now.Sub(started) >= s.Duration {
+		return true
+	}
+	if s.Count > 0 && count >= s.Count {
+		return true
+	}
+	if s.Level > 0 {
+		skill := s.Skill
+		if skill == "" {
+			skill = "combat"
+		}
+		return c.GetSkillLevel(skill) >= s.Level
+	}
+	return false
+}
+
+// Active determines if the step's window is open, an invalid window is never open
+func (s RoutineStep) Active(now time.Time) bool {
+	if s.Window == "" {
+		return true
+	}
+	start, end, err := ParseWindow(s.Window)
+	if err != nil {
+		return false
+	}
+	local := now.Local()
+	minute := time.Duration(local.Hour())*time.Hour + time.Duration(local.Minute())*time.Minute
+	if start <= end {
+		return minute >= start && minute < end
+	}
+	// the window crosses midnight
+	return minute >= start || minute < end
+}
+
+// ParseWindow returns the start and end of a "15:04-15:04" window as offsets from midnight
+func ParseWindow(window string) (time.Duration, time.Duration, error) {
+	var startHour, startMinute, endHour, endMinute int
+	_, err := fmt.Sscanf(window, "%d:%d-%d:%d", &startHour, &startMinute, &endHour, &endMinute)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid window %q: %w", window, err)
+	}
+	for _, hm := range [][2]int{{startHour, startMinute}, {endHour, endMinute}} {
+		if hm[0] < 0 || hm[0] > 23 || hm[1] < 0 || hm[1] > 59 {
+			return 0, 0, fmt.Errorf("invalid window %q: times must be between 00:00 and 23:59", window)
+		}
+	}
+	start := time.Duration(startHour)*time.Hour + time.Duration(startMinute)*time.Minute
+	end := time.Duration(endHour)*time.Hour + time.Duration(endMinute)*time.Minute
+	if start == end {
+		return 0, 0, fmt.Errorf("invalid window %q: start and end must differ", window)
+	}
+	return start, end, nil
+}
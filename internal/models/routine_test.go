@@ -0,0 +1,79 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/promiseofcake/artifactsmmo-go-client/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoutineStepDone(t *testing.T) {
+	started := time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC)
+	c := Character{CharacterSchema: client.CharacterSchema{Level: 11, MiningLevel: 20}}
+
+	tests := []struct {
+		name     string
+		step     RoutineStep
+		count    int
+		elapsed  time.Duration
+		expected bool
+	}{
+		{"no conditions", RoutineStep{Action: "forage"}, 100, 24 * time.Hour, false},
+		{"duration running", RoutineStep{Duration: 45 * time.Minute}, 0, 44 * time.Minute, false},
+		{"duration elapsed", RoutineStep{Duration: 45 * time.Minute}, 0, 45 * time.Minute, true},
+		{"count running", RoutineStep{Count: 10}, 9, 0, false},
+		{"count reached", RoutineStep{Count: 10}, 10, 0, true},
+		{"combat level running", RoutineStep{Level: 12}, 0, 0, false},
+		{"combat level reached", RoutineStep{Level: 11}, 0, 0, true},
+		{"skill level reached", RoutineStep{Skill: "mining", Level: 20}, 0, 0, true},
+		{"any condition", RoutineStep{Duration: time.Hour, Count: 5, Level: 12}, 5, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.step.Done(c, started, tt.count, started.Add(tt.elapsed)))
+		})
+	}
+}
+
+func TestRoutineStepActive(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2024, 7, 1, hour, minute, 0, 0, time.Local)
+	}
+
+	tests := []struct {
+		name     string
+		window   string
+		now      time.Time
+		expected bool
+	}{
+		{"no window", "", at(3, 0), true},
+		{"inside", "09:00-17:30", at(12, 0), true},
+		{"at start", "09:00-17:30", at(9, 0), true},
+		{"at end", "09:00-17:30", at(17, 30), false},
+		{"before", "09:00-17:30", at(8, 59), false},
+		{"overnight late", "22:00-02:00", at(23, 0), true},
+		{"overnight early", "22:00-02:00", at(1, 59), true},
+		{"overnight outside", "22:00-02:00", at(12, 0), false},
+		{"invalid", "noon", at(12, 0), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, RoutineStep{Window: tt.window}.Active(tt.now))
+		})
+	}
+}
+
+func TestParseWindow(t *testing.T) {
+	start, end, err := ParseWindow("18:15-02:00")
+	assert.NoError(t, err)
+	assert.Equal(t, 18*time.Hour+15*time.Minute, start)
+	assert.Equal(t, 2*time.Hour, end)
+
+	for _, window := range []string{"", "18:00", "18:00-24:00", "09:60-10:00", "10:00-10:00"} {
+		_, _, err = ParseWindow(window)
+		assert.Error(t, err, window)
+	}
+}
@@ -0,0 +1,147 @@
+package models
+
+import (
+	"cmp"
+	"math"
+	"slices"
+
+	imath "github.com/promiseofcake/artifactsmmo-engine/internal/math"
+)
+
+// Skill training strategies
+const (
+	TrainWeakest    = "weakest"
+	TrainRoundRobin = "round-robin"
+	TrainXPPerHour  = "xp-per-hour"
+)
+
+// GatheringSkills are the skills trained by gathering resources
+var GatheringSkills = []string{"mining", "woodcutting", "fishing"}
+
+// CraftingSkills are the skills trained by crafting items, mining and woodcutting
+// are trained both by gathering and by refining
+var CraftingSkills = []string{"mining", "woodcutting", "cooking", "alchemy", "weaponcrafting", "gearcrafting", "jewelrycrafting"}
+
+// RefiningSkills are the crafting skills which refine resources from the bank, these
+// are refined by default when a character has not opted into specific skills
+var RefiningSkills = []string{"mining", "woodcutting", "cooking", "alchemy"}
+
+// GearCraftingSkills are the crafting skills trained by the train-crafting action, they craft
+// equipment rather than refining resources
+var GearCraftingSkills = []string{"weaponcrafting", "gearcrafting", "jewelrycrafting"}
+
+// What is done with the items crafted to train a skill
+const (
+	CraftOutputKeep    = "keep"
+	CraftOutputRecycle = "recycle"
+	CraftOutputSell    = "sell"
+)
+
+// CraftTraining configures the train-crafting action
+type CraftTraining struct {
+	// Output is one of keep (default) to bank the crafted items, recycle them back into
+	// materials, or sell them on the grand exchange
+	Output string `mapstructure:"output"`
+}
+
+// IsSkill determines if the given name is a gathering or crafting skill
+func IsSkill(name string) bool {
+	return slices.Contains(GatheringSkills, name) || slices.Contains(CraftingSkills, name)
+}
+
+// SkillTraining configures how a character chooses which skill to train
+type SkillTraining struct {
+	// Strategy is one of weakest (default), round-robin or xp-per-hour
+	Strategy string `mapstructure:"strategy"`
+	// Skills restricts the skills which are trained, an action trains all of its skills if none
+	// of them are listed
+	Skills []string `mapstructure:"skills"`
+	// Targets stops training a skill once it reaches the given level
+	Targets map[string]int `mapstructure:"targets"`
+	// Weights biases the choice of skill, higher is preferred, the default is 1
+	Weights map[string]float64 `mapstructure:"weights"`
+}
+
+// weight returns the configured weight for a skill
+func (t SkillTraining) weight(skill string) float64 {
+	if w, ok := t.Weights[skill]; ok && w > 0 {
+		return w
+	}
+	return 1
+}
+
+// CharacterSkill is a skill chosen to train, with the resource levels to train it on
+type CharacterSkill struct {
+	Code         string
+	CurrentLevel int
+	MinLevel     int
+}
+
+// ChooseSkill picks the next skill to train from the candidates according to the training
+// config. The training skills restrict the candidates only if they name any of them, so one list
+// serves both gathering and crafting. Skills at their target level, or at the level cap, are not
+// trained. The previously trained skill is used for round-robin, and the xp rate for xp-per-hour.
+// If every skill is trained false is returned.
+func (c Character) ChooseSkill(candidates []string, t SkillTraining, caps map[string]int, previous string, rate func(skill string) (float64, bool)) (CharacterSkill, bool) {
+	restricted := slices.ContainsFunc(candidates, func(skill string) bool {
+		return slices.Contains(t.Skills, skill)
+	})
+	var eligible []CharacterSkill
+	for _, skill := range candidates {
+		if restricted && !slices.Contains(t.Skills, skill) {
+			continue
+		}
+
+		level := c.GetSkillLevel(skill)
+		limit, ok := caps[skill]
+		if !ok || limit <= 0 {
+			limit = math.MaxInt
+		}
+		if target, ok := t.Targets[skill]; ok && target > 0 && target < limit {
+			limit = target
+		}
+		if level >= limit {
+			continue
+		}
+
+		eligible = append(eligible, CharacterSkill{
+			Code:         skill,
+			CurrentLevel: level,
+			MinLevel:     imath.Max(0, level-10),
+		})
+	}
+
+	if len(eligible) == 0 {
+		return CharacterSkill{}, false
+	}
+
+	switch t.Strategy {
+	case TrainRoundRobin:
+		// pick the first eligible skill after the previous one, in candidate order
+		next := slices.Index(candidates, previous) + 1
+		slices.SortStableFunc(eligible, func(a, b CharacterSkill) int {
+			ia := (slices.Index(candidates, a.Code) - next + len(candidates)) % len(candidates)
+			ib := (slices.Index(candidates, b.Code) - next + len(candidates)) % len(candidates)
+			return cmp.Compare(ia, ib)
+		})
+	case TrainXPPerHour:
+		// prefer the highest weighted rate, skills with no history are tried first
+		score := func(s CharacterSkill) float64 {
+			r, ok := rate(s.Code)
+			if !ok {
+				return math.Inf(1)
+			}
+			return r * t.weight(s.Code)
+		}
+		slices.SortStableFunc(eligible, func(a, b CharacterSkill) int {
+			return cmp.Compare(score(b), score(a))
+		})
+	default:
+		// prefer the lowest weighted level
+		slices.SortStableFunc(eligible, func(a, b CharacterSkill) int {
+			return cmp.Compare(float64(a.CurrentLevel)/t.weight(a.Code), float64(b.CurrentLevel)/t.weight(b.Code))
+		})
+	}
+
+	return eligible[0], true
+}
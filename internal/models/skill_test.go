@@ -0,0 +1,68 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/promiseofcake/artifactsmmo-go-client/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChooseSkill(t *testing.T) {
+	c := Character{CharacterSchema: client.CharacterSchema{
+		MiningLevel:      12,
+		WoodcuttingLevel: 5,
+		FishingLevel:     35,
+	}}
+	caps := map[string]int{"mining": 35, "woodcutting": 35, "fishing": 35}
+	noRate := func(string) (float64, bool) { return 0, false }
+
+	tests := []struct {
+		name     string
+		training SkillTraining
+		previous string
+		rate     func(string) (float64, bool)
+		expected string
+		ok       bool
+	}{
+		{"weakest", SkillTraining{}, "", noRate, "woodcutting", true},
+		{"weighted", SkillTraining{Weights: map[string]float64{"mining": 3}}, "", noRate, "mining", true},
+		{"target reached", SkillTraining{Targets: map[string]int{"woodcutting": 5}}, "", noRate, "mining", true},
+		{"restricted", SkillTraining{Skills: []string{"mining"}}, "", noRate, "mining", true},
+		{"all trained", SkillTraining{Skills: []string{"fishing"}}, "", noRate, "", false},
+		{"restricted to other skills", SkillTraining{Skills: []string{"weaponcrafting"}}, "", noRate, "woodcutting", true},
+		{"round robin start", SkillTraining{Strategy: TrainRoundRobin}, "", noRate, "mining", true},
+		{"round robin next", SkillTraining{Strategy: TrainRoundRobin}, "mining", noRate, "woodcutting", true},
+		{"round robin wraps past capped", SkillTraining{Strategy: TrainRoundRobin}, "woodcutting", noRate, "mining", true},
+		{
+			"xp per hour",
+			SkillTraining{Strategy: TrainXPPerHour},
+			"",
+			func(s string) (float64, bool) { return map[string]float64{"mining": 900, "woodcutting": 1200}[s], true },
+			"woodcutting",
+			true,
+		},
+		{
+			"xp per hour explores",
+			SkillTraining{Strategy: TrainXPPerHour},
+			"",
+			func(s string) (float64, bool) { return 900, s == "woodcutting" },
+			"mining",
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			skill, ok := c.ChooseSkill(GatheringSkills, tt.training, caps, tt.previous, tt.rate)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, skill.Code)
+		})
+	}
+}
+
+func TestChooseSkillMinLevel(t *testing.T) {
+	c := Character{CharacterSchema: client.CharacterSchema{MiningLevel: 15}}
+	skill, ok := c.ChooseSkill([]string{"mining"}, SkillTraining{}, nil, "", nil)
+	assert.True(t, ok)
+	assert.Equal(t, CharacterSkill{Code: "mining", CurrentLevel: 15, MinLevel: 5}, skill)
+}
@@ -0,0 +1,95 @@
+package models
+
+import "math"
+
+// maxTripPermutations is the most stops a trip reorders, larger trips keep their given order
+const maxTripPermutations = 7
+
+// TripStop is a stop of a planned trip, the index of the stop as given and the tile chosen for it
+type TripStop struct {
+	Index    int
+	Location Location
+}
+
+// PlanTrip chooses a tile for each stop, from its candidates, and unless ordered the order to
+// visit them in, so the total distance moved from the start is the smallest. Every stop needs at
+// least one candidate. The planned stops are returned with the total distance.
+func PlanTrip(start Coords, stops []Locations, ordered bool) ([]TripStop, int) {
+	if len(stops) == 0 {
+		return nil, 0
+	}
+
+	order := make([]int, len(stops))
+	for i := range order {
+		order[i] = i
+	}
+	if ordered || len(stops) > maxTripPermutations {
+		return planTiles(start, stops, order)
+	}
+
+	var best []TripStop
+	bestDistance := math.MaxInt
+	permute(order, 0, func(p []int) {
+		trip, distance := planTiles(start, stops, p)
+		if distance < bestDistance {
+			best, bestDistance = trip, distance
+		}
+	})
+	return best, bestDistance
+}
+
+// planTiles chooses the tile for each stop visited in the given order, walking forward through
+// the stops keeping the shortest distance to reach each of a stop's tiles
+func planTiles(start Coords, stops []Locations, order []int) ([]TripStop, int) {
+	type reach struct {
+		distance int
+		from     int
+	}
+
+	layers := make([][]reach, len(order))
+	for n, i := range order {
+		layers[n] = make([]reach, len(stops[i]))
+		for t, tile := range stops[i] {
+			layers[n][t] = reach{distance: math.MaxInt, from: -1}
+			if n == 0 {
+				layers[n][t].distance = CalculateDistance(start, tile.Coords)
+				continue
+			}
+			for p, prev := range stops[order[n-1]] {
+				d := layers[n-1][p].distance + CalculateDistance(prev.Coords, tile.Coords)
+				if d < layers[n][t].distance {
+					layers[n][t] = reach{distance: d, from: p}
+				}
+			}
+		}
+	}
+
+	last := len(order) - 1
+	tile := 0
+	for t, r := range layers[last] {
+		if r.distance < layers[last][tile].distance {
+			tile = t
+		}
+	}
+	distance := layers[last][tile].distance
+
+	trip := make([]TripStop, len(order))
+	for n := last; n >= 0; n-- {
+		trip[n] = TripStop{Index: order[n], Location: stops[order[n]][tile]}
+		tile = layers[n][tile].from
+	}
+	return trip, distance
+}
+
+// permute calls fn with every permutation of the indexes from k onwards, in place
+func permute(p []int, k int, fn func([]int)) {
+	if k == len(p) {
+		fn(p)
+		return
+	}
+	for i := k; i < len(p); i++ {
+		p[k], p[i] = p[i], p[k]
+		permute(p, k+1, fn)
+		p[k], p[i] = p[i], p[k]
+	}
+}
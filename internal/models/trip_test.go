@@ -0,0 +1,45 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanTrip(t *testing.T) {
+	bankWest := Location{Code: "bank", Type: "bank", Coords: Coords{X: -5, Y: 0}}
+	bankEast := Location{Code: "bank", Type: "bank", Coords: Coords{X: 4, Y: 1}}
+	forge := Location{Code: "weaponcrafting", Type: "workshop", Coords: Coords{X: 6, Y: 1}}
+	copper := Location{Code: "copper_rocks", Type: "resource", Coords: Coords{X: 2, Y: 0}}
+	banks := Locations{bankWest, bankEast}
+
+	// nothing to plan
+	trip, distance := PlanTrip(Coords{}, nil, false)
+	assert.Empty(t, trip)
+	assert.Equal(t, 0, distance)
+
+	// the nearest bank to the start is west, but east is on the way to the workshop
+	trip, distance = PlanTrip(Coords{X: -2}, []Locations{banks, {forge}, banks}, true)
+	assert.Equal(t, []TripStop{
+		{Index: 0, Location: bankEast},
+		{Index: 1, Location: forge},
+		{Index: 2, Location: bankEast},
+	}, trip)
+	assert.Equal(t, 7+2+2, distance)
+
+	// unordered stops are visited in the order moving the least
+	trip, distance = PlanTrip(Coords{}, []Locations{{forge}, {copper}}, false)
+	assert.Equal(t, []TripStop{
+		{Index: 1, Location: copper},
+		{Index: 0, Location: forge},
+	}, trip)
+	assert.Equal(t, 2+5, distance)
+
+	// ordered stops keep their order
+	trip, distance = PlanTrip(Coords{}, []Locations{{forge}, {copper}}, true)
+	assert.Equal(t, []TripStop{
+		{Index: 0, Location: forge},
+		{Index: 1, Location: copper},
+	}, trip)
+	assert.Equal(t, 7+5, distance)
+}